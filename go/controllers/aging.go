@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"bookclubbot.com/main/models"
+)
+
+// agedVotes wraps a strategy so it sees vote counts weighted by how recently they were cast.
+type agedVotes struct {
+	SelectionStrategy
+	scores map[string]float64
+}
+
+func (s agedVotes) Select(candidates []models.BookEntry, history []models.BookEntry) (models.BookEntry, error) {
+	aged := []models.BookEntry{}
+	for _, book := range candidates {
+		if score, ok := s.scores[book.Id]; ok {
+			book.AgedVotes = &score
+		}
+		aged = append(aged, book)
+	}
+	return s.SelectionStrategy.Select(aged, history)
+}
+
+// WithVoteAging applies the club's vote decay settings to a strategy. When decay is
+// turned off the strategy is returned unchanged.
+func WithVoteAging(strategy SelectionStrategy, t *models.ClubTable, now time.Time) SelectionStrategy {
+	if t.Settings.VoteHalfLifeDays <= 0 && t.Settings.VoteWindowMonths <= 0 {
+		return strategy
+	}
+	scores := map[string]float64{}
+	for _, book := range t.BookPool {
+		scores[book.Id] = AgedVoteScore(t, book, now)
+	}
+	return agedVotes{SelectionStrategy: strategy, scores: scores}
+}
+
+// AgedVoteScore weighs each vote for a book by its age. Votes counted before the ledger
+// existed are aged from when the book was recommended.
+func AgedVoteScore(t *models.ClubTable, book models.BookEntry, now time.Time) float64 {
+	score := 0.0
+	ledger_votes := 0
+	for _, v := range t.Votes {
+		if v.BookId != book.Id {
+			continue
+		}
+		ledger_votes++
+		score += voteWeight(t.Settings, v.Timestamp, now)
+	}
+	if legacy_votes := book.Votes - ledger_votes; legacy_votes > 0 {
+		score += float64(legacy_votes) * voteWeight(t.Settings, book.CreatedAt, now)
+	}
+	return score
+}
+
+func voteWeight(settings models.ClubSettings, cast time.Time, now time.Time) float64 {
+	// Votes without a timestamp predate aging, so they keep their full weight.
+	if cast.IsZero() {
+		return 1
+	}
+	if settings.VoteWindowMonths > 0 && cast.Before(now.AddDate(0, -settings.VoteWindowMonths, 0)) {
+		return 0
+	}
+	if settings.VoteHalfLifeDays > 0 {
+		age_days := now.Sub(cast).Hours() / 24
+		return math.Pow(0.5, max(age_days, 0)/float64(settings.VoteHalfLifeDays))
+	}
+	return 1
+}
+
+// LastActivity is the latest of when a book was recommended, revived or voted for.
+func LastActivity(t *models.ClubTable, book models.BookEntry) time.Time {
+	latest := book.CreatedAt
+	if book.RevivedAt.After(latest) {
+		latest = book.RevivedAt
+	}
+	for _, v := range t.Votes {
+		if v.BookId == book.Id && v.Timestamp.After(latest) {
+			latest = v.Timestamp
+		}
+	}
+	return latest
+}
+
+// ArchiveInactiveBooks archives unread books with no activity within the club's
+// archive window and returns the books it archived.
+func ArchiveInactiveBooks(t *models.ClubTable, now time.Time) []models.BookEntry {
+	archived := []models.BookEntry{}
+	if t.Settings.ArchiveAfterMonths <= 0 {
+		return archived
+	}
+	cutoff := now.AddDate(0, -t.Settings.ArchiveAfterMonths, 0)
+	for i, book := range t.BookPool {
+		if book.Read || book.Archived || book.Queued {
+			continue
+		}
+		last_activity := LastActivity(t, book)
+		// Books from before activity was tracked are left alone.
+		if last_activity.IsZero() || !last_activity.Before(cutoff) {
+			continue
+		}
+		t.BookPool[i].Archived = true
+		archived = append(archived, t.BookPool[i])
+	}
+	return archived
+}
+
+// ReviveBook brings an archived book back into the running.
+func ReviveBook(books []models.BookEntry, bookId string, now time.Time) (models.BookEntry, error) {
+	for i, book := range books {
+		if book.Id != bookId {
+			continue
+		}
+		if !book.Archived {
+			return models.BookEntry{}, fmt.Errorf("'%s' isn't archived.", book.Name)
+		}
+		books[i].Archived = false
+		books[i].RevivedAt = now
+		return books[i], nil
+	}
+	return models.BookEntry{}, fmt.Errorf("Book with ID '%s' not found", bookId)
+}
+
+// SetVoteAging changes how votes age and when inactive books are archived.
+func SetVoteAging(settings *models.ClubSettings, halfLifeDays int, windowMonths int, archiveAfterMonths int) error {
+	if halfLifeDays < 0 || windowMonths < 0 || archiveAfterMonths < 0 {
+		return fmt.Errorf("Vote aging settings can't be negative.")
+	}
+	settings.VoteHalfLifeDays = halfLifeDays
+	settings.VoteWindowMonths = windowMonths
+	settings.ArchiveAfterMonths = archiveAfterMonths
+	return nil
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"bookclubbot.com/main/models"
+)
+
+func TestAgedVoteScore_TestCases(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		settings models.ClubSettings
+		want     float64
+	}{
+		{
+			name: "No aging counts every vote",
+			want: 3,
+		},
+		{
+			name:     "Half-life halves votes as they age",
+			settings: models.ClubSettings{VoteHalfLifeDays: 30},
+			// One vote today, one 30 days old and one 365 days old.
+			want: 1 + 0.5 + math.Pow(0.5, 365.0/30),
+		},
+		{
+			name:     "Window drops votes older than N months",
+			settings: models.ClubSettings{VoteWindowMonths: 6},
+			want:     2,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			table := models.ClubTable{
+				BookPool: []models.BookEntry{{Id: "book-1", Votes: 3}},
+				Votes: []models.VoteEntry{
+					{UserId: "a", BookId: "book-1", Timestamp: now},
+					{UserId: "b", BookId: "book-1", Timestamp: now.AddDate(0, 0, -30)},
+					{UserId: "c", BookId: "book-1", Timestamp: now.AddDate(0, 0, -365)},
+				},
+				Settings: tc.settings,
+			}
+			got := AgedVoteScore(&table, table.BookPool[0], now)
+			if math.Abs(got-tc.want) > 1e-9 {
+				t.Errorf("Expected score %f, got %f", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestWithVoteAging_PrefersRecentVotes(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	table := models.ClubTable{
+		BookPool: []models.BookEntry{{Id: "old", Votes: 3}, {Id: "new", Votes: 2}},
+		Votes: []models.VoteEntry{
+			{UserId: "a", BookId: "old", Timestamp: now.AddDate(-1, 0, 0)},
+			{UserId: "b", BookId: "old", Timestamp: now.AddDate(-1, 0, 0)},
+			{UserId: "c", BookId: "old", Timestamp: now.AddDate(-1, 0, 0)},
+			{UserId: "a", BookId: "new", Timestamp: now},
+			{UserId: "b", BookId: "new", Timestamp: now},
+		},
+		Settings: models.ClubSettings{VoteHalfLifeDays: 30},
+	}
+	got, err := selectNextBookWith(WithVoteAging(HighestVotes{}, &table, now), table.BookPool, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got.Id != "new" {
+		t.Errorf("Expected recent votes to win, got %s", got.Id)
+	}
+}
+
+func TestArchiveAndReviveBooks(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	table := models.ClubTable{
+		BookPool: []models.BookEntry{
+			{Id: "stale", Name: "Stale Book", CreatedAt: now.AddDate(-2, 0, 0)},
+			{Id: "voted", Name: "Voted Book", CreatedAt: now.AddDate(-2, 0, 0)},
+			{Id: "fresh", Name: "Fresh Book", CreatedAt: now.AddDate(0, -1, 0)},
+			{Id: "legacy", Name: "Legacy Book"},
+		},
+		Votes:    []models.VoteEntry{{UserId: "a", BookId: "voted", Timestamp: now.AddDate(0, -2, 0)}},
+		Settings: models.ClubSettings{ArchiveAfterMonths: 12},
+	}
+
+	archived := ArchiveInactiveBooks(&table, now)
+	if len(archived) != 1 || archived[0].Id != "stale" {
+		t.Fatalf("Expected only the stale book to be archived, got %v", archived)
+	}
+	if _, err := selectNextBookWith(HighestVotes{}, table.BookPool[:1], nil); err == nil {
+		t.Errorf("An archived book can still be selected")
+	}
+
+	if _, err := ReviveBook(table.BookPool, "fresh", now); err == nil {
+		t.Errorf("Expected an error reviving a book that isn't archived")
+	}
+	revived, err := ReviveBook(table.BookPool, "stale", now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if revived.Archived || table.BookPool[0].Archived {
+		t.Errorf("The book was not revived")
+	}
+	if len(ArchiveInactiveBooks(&table, now)) != 0 {
+		t.Errorf("A revived book was archived again right away")
+	}
+}
+
+func TestWithVoteAging_KeepsFractionalScores(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	table := models.ClubTable{
+		// A single vote that has decayed to well under half its weight still beats no votes.
+		BookPool: []models.BookEntry{{Id: "unvoted"}, {Id: "faded", Votes: 1}},
+		Votes:    []models.VoteEntry{{UserId: "a", BookId: "faded", Timestamp: now.AddDate(0, 0, -40)}},
+		Settings: models.ClubSettings{VoteHalfLifeDays: 30},
+	}
+	got, err := selectNextBookWith(WithVoteAging(HighestVotes{}, &table, now), table.BookPool, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got.Id != "faded" {
+		t.Errorf("Expected the decayed vote to still count, got %s", got.Id)
+	}
+}
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	"bookclubbot.com/main/models"
+)
+
+// Announcement is what the club needs to know about the next meetup.
+type Announcement struct {
+	Meetup models.ScheduleEntry
+	// The book being discussed. Its ID is empty if no book is scheduled yet.
+	Book models.BookEntry
+	// The cafe hosting the meetup. Its ID is empty for virtual meetups.
+	Cafe models.CafeEntry
+	// Which of the book's meetups this is, counting from 1, and how many it has.
+	Part  int
+	Parts int
+	// The page to have read up to by the meetup, or 0 if the book's length isn't known.
+	TargetPage int
+}
+
+// NextMeetup returns the index of the first meetup on or after today's date.
+func NextMeetup(schedules []models.ScheduleEntry, now time.Time) (int, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for i, s := range schedules {
+		date, err := time.Parse(TIME_FORMAT, s.Date)
+		if err != nil {
+			continue
+		}
+		if !date.Before(today) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("No upcoming meetups are scheduled.")
+}
+
+// ComposeAnnouncement gathers the details of the next meetup. Books are read across
+// consecutive meetups, so the reading target splits the book evenly between them.
+func ComposeAnnouncement(t *models.ClubTable, now time.Time) (Announcement, error) {
+	index, err := NextMeetup(t.Schedule, now)
+	if err != nil {
+		return Announcement{}, err
+	}
+	a := Announcement{Meetup: t.Schedule[index]}
+
+	if a.Meetup.NeedsCafe() && a.Meetup.CafeId != "" {
+		a.Cafe, err = t.GetCafeById(a.Meetup.CafeId)
+		if err != nil {
+			return Announcement{}, err
+		}
+	}
+	if a.Meetup.BookId == "" {
+		return a, nil
+	}
+	a.Book, err = t.GetBookById(a.Meetup.BookId)
+	if err != nil {
+		return Announcement{}, err
+	}
+
+	first := index
+	for first > 0 && t.Schedule[first-1].BookId == a.Meetup.BookId {
+		first--
+	}
+	last := index
+	for last < len(t.Schedule)-1 && t.Schedule[last+1].BookId == a.Meetup.BookId {
+		last++
+	}
+	a.Part = index - first + 1
+	a.Parts = last - first + 1
+	if a.Book.PageCount > 0 {
+		// Round up so the last part always reaches the end of the book.
+		a.TargetPage = (a.Book.PageCount*a.Part + a.Parts - 1) / a.Parts
+	}
+	return a, nil
+}
+
+// ClubLocation is the time zone the club's jobs run in.
+func ClubLocation(settings models.ClubSettings) (*time.Location, error) {
+	if settings.TimeZone == "" {
+		return time.Local, nil
+	}
+	location, err := time.LoadLocation(settings.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("Unknown time zone '%s'.", settings.TimeZone)
+	}
+	return location, nil
+}
+
+// SetWeeklyAnnouncement schedules the weekly announcement for a weekday and a local time
+// such as "18:00". A time zone can be given too. A negative weekday turns announcements off.
+func SetWeeklyAnnouncement(settings *models.ClubSettings, weekday int, clock string, timezone string) error {
+	if weekday < 0 {
+		settings.AnnouncementSchedule = ""
+		return nil
+	}
+	if weekday > int(time.Saturday) {
+		return fmt.Errorf("Unknown day of the week.")
+	}
+	at, err := time.Parse("15:04", clock)
+	if err != nil {
+		return fmt.Errorf("Times look like 18:00, not '%s'.", clock)
+	}
+	if timezone != "" {
+		_, err = time.LoadLocation(timezone)
+		if err != nil {
+			return fmt.Errorf("Unknown time zone '%s'.", timezone)
+		}
+		settings.TimeZone = timezone
+	}
+	settings.AnnouncementSchedule = fmt.Sprintf("%d %d * * %d", at.Minute(), at.Hour(), weekday)
+	return nil
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"bookclubbot.com/main/models"
+)
+
+func announcementTable() models.ClubTable {
+	return models.ClubTable{
+		CafePool: []models.CafeEntry{{Id: "cafe-1", Name: "Hot Java"}},
+		BookPool: []models.BookEntry{
+			{Id: "book-1", Name: "The Hobbit", BookMetadata: models.BookMetadata{PageCount: 310}},
+			{Id: "book-2", Name: "Dune"},
+		},
+		Schedule: []models.ScheduleEntry{
+			{Id: "s1", Date: "October 3, 2026", BookId: "book-1", CafeId: "cafe-1"},
+			{Id: "s2", Date: "October 10, 2026", BookId: "book-1", CafeId: "cafe-1"},
+			{Id: "s3", Date: "October 17, 2026", BookId: "book-1", Kind: models.MeetingVirtual},
+			{Id: "s4", Date: "October 24, 2026", BookId: "book-2", CafeId: "cafe-1"},
+		},
+	}
+}
+
+func TestComposeAnnouncement_SplitsBookAcrossMeetups(t *testing.T) {
+	table := announcementTable()
+	a, err := ComposeAnnouncement(&table, time.Date(2026, 10, 6, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if a.Meetup.Id != "s2" || a.Book.Name != "The Hobbit" || a.Cafe.Name != "Hot Java" {
+		t.Errorf("Unexpected announcement %+v", a)
+	}
+	if a.Part != 2 || a.Parts != 3 || a.TargetPage != 207 {
+		t.Errorf("Expected part 2 of 3 up to page 207, got part %d of %d up to page %d", a.Part, a.Parts, a.TargetPage)
+	}
+}
+
+func TestComposeAnnouncement_MeetupOnTheDay(t *testing.T) {
+	table := announcementTable()
+	a, err := ComposeAnnouncement(&table, time.Date(2026, 10, 17, 18, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if a.Meetup.Id != "s3" || a.Cafe.Id != "" || a.TargetPage != 310 {
+		t.Errorf("Expected the virtual meetup to finish the book, got %+v", a)
+	}
+}
+
+func TestComposeAnnouncement_UnknownLength(t *testing.T) {
+	table := announcementTable()
+	a, err := ComposeAnnouncement(&table, time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if a.Book.Name != "Dune" || a.Part != 1 || a.Parts != 1 || a.TargetPage != 0 {
+		t.Errorf("Unexpected announcement %+v", a)
+	}
+
+	_, err = ComposeAnnouncement(&table, time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC))
+	if err == nil {
+		t.Errorf("Expected an error with no upcoming meetups")
+	}
+}
+
+func TestSetWeeklyAnnouncement(t *testing.T) {
+	settings := models.ClubSettings{}
+	err := SetWeeklyAnnouncement(&settings, int(time.Thursday), "18:30", "America/Chicago")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if settings.AnnouncementSchedule != "30 18 * * 4" || settings.TimeZone != "America/Chicago" {
+		t.Errorf("Unexpected settings %+v", settings)
+	}
+
+	for _, clock := range []string{"6pm", "25:00"} {
+		err = SetWeeklyAnnouncement(&settings, int(time.Friday), clock, "")
+		if err == nil {
+			t.Errorf("Expected '%s' to be rejected", clock)
+		}
+	}
+	err = SetWeeklyAnnouncement(&settings, int(time.Friday), "18:00", "Mars/Olympus_Mons")
+	if err == nil {
+		t.Errorf("Expected an unknown time zone to be rejected")
+	}
+	if settings.AnnouncementSchedule != "30 18 * * 4" {
+		t.Errorf("Expected failed changes to leave the schedule alone, got %s", settings.AnnouncementSchedule)
+	}
+
+	err = SetWeeklyAnnouncement(&settings, -1, "", "")
+	if err != nil || settings.AnnouncementSchedule != "" {
+		t.Errorf("Expected announcements to be turned off, got %v %+v", err, settings)
+	}
+}
@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"bookclubbot.com/main/models"
+)
+
+// Monthly turnout charts cover at most this many months.
+const maxTurnoutMonths = 12
+
+// MeetupHasHappened reports whether a meetup's date has come, so attendance can be taken.
+// Meetups without a date haven't happened.
+func MeetupHasHappened(meetup models.ScheduleEntry, now time.Time) bool {
+	date, err := time.Parse(TIME_FORMAT, meetup.Date)
+	if err != nil {
+		return false
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return !date.After(today)
+}
+
+// RecordAttendance stores who came to a meetup, replacing any earlier record for it.
+func RecordAttendance(t *models.ClubTable, scheduleId string, userIds []string, now time.Time) error {
+	meetup, err := t.GetScheduleById(scheduleId)
+	if err != nil {
+		return fmt.Errorf("That meetup is no longer on the schedule.")
+	}
+	if _, err := time.Parse(TIME_FORMAT, meetup.Date); err != nil {
+		return fmt.Errorf("That meetup doesn't have a date yet.")
+	}
+	if !MeetupHasHappened(meetup, now) {
+		return fmt.Errorf("That meetup hasn't happened yet.")
+	}
+
+	entry := models.AttendanceEntry{
+		ScheduleId: meetup.Id,
+		Date:       meetup.Date,
+		BookId:     meetup.BookId,
+		Attendees:  slices.Clone(userIds),
+	}
+	if meetup.NeedsCafe() {
+		entry.CafeId = meetup.CafeId
+	}
+	index := slices.IndexFunc(t.Attendance, func(a models.AttendanceEntry) bool {
+		return a.ScheduleId == scheduleId
+	})
+	if index >= 0 {
+		t.Attendance[index] = entry
+	} else {
+		t.Attendance = append(t.Attendance, entry)
+	}
+	slices.SortStableFunc(t.Attendance, func(a, b models.AttendanceEntry) int {
+		return attendanceDate(a).Compare(attendanceDate(b))
+	})
+	return nil
+}
+
+// GetAttendance returns the attendance recorded for a meetup, if any.
+func GetAttendance(t *models.ClubTable, scheduleId string) (models.AttendanceEntry, bool) {
+	for _, a := range t.Attendance {
+		if a.ScheduleId == scheduleId {
+			return a, true
+		}
+	}
+	return models.AttendanceEntry{}, false
+}
+
+func attendanceDate(a models.AttendanceEntry) time.Time {
+	date, _ := time.Parse(TIME_FORMAT, a.Date)
+	return date
+}
+
+// MonthlyTurnout is how many attendances were recorded in a month.
+type MonthlyTurnout struct {
+	Month time.Time
+	Count int
+}
+
+type MemberStats struct {
+	// How many recorded meetups the member came to, out of how many.
+	Attended int
+	Meetups  int
+	// Consecutive recorded meetups attended, up to the latest, and the longest run ever.
+	CurrentStreak int
+	LongestStreak int
+	// Read books whose final recorded meetup the member came to.
+	BooksFinished int
+	// The cafe the member has come to most. Empty if they haven't come to one.
+	FavoriteCafeId string
+	Monthly        []MonthlyTurnout
+}
+
+// ComputeMemberStats summarizes a member's attendance.
+func ComputeMemberStats(t *models.ClubTable, userId string) MemberStats {
+	stats := MemberStats{Meetups: len(t.Attendance)}
+	finalMeetups := map[string]models.AttendanceEntry{}
+	cafeVisits := map[string]int{}
+	for _, a := range t.Attendance {
+		if a.BookId != "" {
+			finalMeetups[a.BookId] = a
+		}
+		if !slices.Contains(a.Attendees, userId) {
+			stats.CurrentStreak = 0
+			continue
+		}
+		stats.Attended++
+		stats.CurrentStreak++
+		stats.LongestStreak = max(stats.LongestStreak, stats.CurrentStreak)
+		if a.CafeId != "" {
+			cafeVisits[a.CafeId]++
+		}
+	}
+	for bookId, final := range finalMeetups {
+		book, err := t.GetBookById(bookId)
+		if err == nil && book.Read && slices.Contains(final.Attendees, userId) {
+			stats.BooksFinished++
+		}
+	}
+	stats.FavoriteCafeId = mostVisited(t.Attendance, cafeVisits)
+	stats.Monthly = monthlyTurnout(t.Attendance, func(a models.AttendanceEntry) int {
+		if slices.Contains(a.Attendees, userId) {
+			return 1
+		}
+		return 0
+	})
+	return stats
+}
+
+type ClubStats struct {
+	Meetups        int
+	AverageTurnout float64
+	// Books the club has read.
+	BooksFinished int
+	// The cafe with the most attendances. Empty if no in-person meetups were recorded.
+	MostAttendedCafeId string
+	// The member with the longest current attendance streak, if anyone has one.
+	TopStreakUserId string
+	TopStreak       int
+	Monthly         []MonthlyTurnout
+}
+
+// ComputeClubStats summarizes attendance across the club.
+func ComputeClubStats(t *models.ClubTable) ClubStats {
+	stats := ClubStats{Meetups: len(t.Attendance)}
+	total := 0
+	cafeVisits := map[string]int{}
+	members := []string{}
+	for _, a := range t.Attendance {
+		total += len(a.Attendees)
+		if a.CafeId != "" {
+			cafeVisits[a.CafeId] += len(a.Attendees)
+		}
+		for _, userId := range a.Attendees {
+			if !slices.Contains(members, userId) {
+				members = append(members, userId)
+			}
+		}
+	}
+	if stats.Meetups > 0 {
+		stats.AverageTurnout = float64(total) / float64(stats.Meetups)
+	}
+	for _, book := range t.BookPool {
+		if book.Read {
+			stats.BooksFinished++
+		}
+	}
+	for _, userId := range members {
+		streak := ComputeMemberStats(t, userId).CurrentStreak
+		if streak > stats.TopStreak {
+			stats.TopStreak = streak
+			stats.TopStreakUserId = userId
+		}
+	}
+	stats.MostAttendedCafeId = mostVisited(t.Attendance, cafeVisits)
+	stats.Monthly = monthlyTurnout(t.Attendance, func(a models.AttendanceEntry) int {
+		return len(a.Attendees)
+	})
+	return stats
+}
+
+// mostVisited picks the cafe with the most visits, breaking ties by which was visited first.
+func mostVisited(attendance []models.AttendanceEntry, visits map[string]int) string {
+	best := ""
+	for _, a := range attendance {
+		if a.CafeId != "" && visits[a.CafeId] > visits[best] {
+			best = a.CafeId
+		}
+	}
+	return best
+}
+
+// monthlyTurnout totals count over each month from the first recorded meetup to the last,
+// including quiet months, keeping only the most recent months.
+func monthlyTurnout(attendance []models.AttendanceEntry, count func(models.AttendanceEntry) int) []MonthlyTurnout {
+	months := []MonthlyTurnout{}
+	for _, a := range attendance {
+		date := attendanceDate(a)
+		if date.IsZero() {
+			continue
+		}
+		month := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if len(months) == 0 {
+			months = append(months, MonthlyTurnout{Month: month})
+		}
+		for months[len(months)-1].Month.Before(month) {
+			months = append(months, MonthlyTurnout{Month: months[len(months)-1].Month.AddDate(0, 1, 0)})
+		}
+		months[len(months)-1].Count += count(a)
+	}
+	if len(months) > maxTurnoutMonths {
+		months = months[len(months)-maxTurnoutMonths:]
+	}
+	return months
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"bookclubbot.com/main/models"
+)
+
+func TestRecordAttendance(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	table := models.ClubTable{Schedule: []models.ScheduleEntry{
+		{Id: "past", Date: "October 17, 2026", BookId: "b1", CafeId: "c1"},
+		{Id: "virtual", Date: "October 10, 2026", BookId: "b1", CafeId: "c1", Kind: models.MeetingVirtual},
+		{Id: "future", Date: "October 24, 2026"},
+	}}
+
+	if err := RecordAttendance(&table, "past", []string{"alice"}, now); err != nil {
+		t.Fatalf("Internal error %v", err)
+	}
+	if err := RecordAttendance(&table, "past", []string{"alice", "bob"}, now); err != nil {
+		t.Fatalf("Internal error %v", err)
+	}
+	if err := RecordAttendance(&table, "virtual", []string{"carol"}, now); err != nil {
+		t.Fatalf("Internal error %v", err)
+	}
+	if len(table.Attendance) != 2 {
+		t.Fatalf("Expected a record per meetup, got %v", table.Attendance)
+	}
+	if table.Attendance[0].ScheduleId != "virtual" || table.Attendance[0].CafeId != "" {
+		t.Errorf("Expected records in date order without a cafe for virtual meetups, got %v", table.Attendance)
+	}
+	if len(table.Attendance[1].Attendees) != 2 {
+		t.Errorf("Expected the second record to replace the first, got %v", table.Attendance[1])
+	}
+	if err := RecordAttendance(&table, "future", []string{"alice"}, now); err == nil {
+		t.Errorf("Expected an error recording a meetup that hasn't happened")
+	}
+}
+
+func attendanceFixture() models.ClubTable {
+	return models.ClubTable{
+		BookPool: []models.BookEntry{{Id: "b1", Read: true}, {Id: "b2"}},
+		Attendance: []models.AttendanceEntry{
+			{ScheduleId: "s1", Date: "July 4, 2026", BookId: "b1", CafeId: "c1", Attendees: []string{"alice", "bob"}},
+			{ScheduleId: "s2", Date: "July 11, 2026", BookId: "b1", CafeId: "c2", Attendees: []string{"alice"}},
+			{ScheduleId: "s3", Date: "September 5, 2026", BookId: "b2", CafeId: "c2", Attendees: []string{"bob"}},
+			{ScheduleId: "s4", Date: "September 12, 2026", BookId: "b2", CafeId: "c2", Attendees: []string{"alice", "bob"}},
+		},
+	}
+}
+
+func TestComputeMemberStats(t *testing.T) {
+	table := attendanceFixture()
+	stats := ComputeMemberStats(&table, "alice")
+
+	if stats.Attended != 3 || stats.Meetups != 4 {
+		t.Errorf("Expected 3 of 4 meetups, got %d of %d", stats.Attended, stats.Meetups)
+	}
+	if stats.CurrentStreak != 1 || stats.LongestStreak != 2 {
+		t.Errorf("Expected streaks of 1 and 2, got %d and %d", stats.CurrentStreak, stats.LongestStreak)
+	}
+	if stats.BooksFinished != 1 {
+		t.Errorf("Expected 1 book finished, got %d", stats.BooksFinished)
+	}
+	if stats.FavoriteCafeId != "c2" {
+		t.Errorf("Expected c2 as the favorite cafe, got %s", stats.FavoriteCafeId)
+	}
+	expected := []int{2, 0, 1}
+	if len(stats.Monthly) != len(expected) {
+		t.Fatalf("Expected July through September, got %v", stats.Monthly)
+	}
+	for n, count := range expected {
+		if stats.Monthly[n].Count != count {
+			t.Errorf("Expected %d meetups in %v, got %d", count, stats.Monthly[n].Month, stats.Monthly[n].Count)
+		}
+	}
+}
+
+func TestComputeClubStats(t *testing.T) {
+	table := attendanceFixture()
+	stats := ComputeClubStats(&table)
+
+	if stats.Meetups != 4 || stats.AverageTurnout != 1.5 {
+		t.Errorf("Expected 4 meetups averaging 1.5, got %d averaging %v", stats.Meetups, stats.AverageTurnout)
+	}
+	if stats.BooksFinished != 1 {
+		t.Errorf("Expected 1 book finished, got %d", stats.BooksFinished)
+	}
+	if stats.MostAttendedCafeId != "c2" {
+		t.Errorf("Expected c2 as the most attended cafe, got %s", stats.MostAttendedCafeId)
+	}
+	if stats.TopStreakUserId != "bob" || stats.TopStreak != 2 {
+		t.Errorf("Expected bob to have the top streak of 2, got %s with %d", stats.TopStreakUserId, stats.TopStreak)
+	}
+	if len(stats.Monthly) != 3 || stats.Monthly[0].Count != 3 || stats.Monthly[2].Count != 3 {
+		t.Errorf("Unexpected monthly turnout %v", stats.Monthly)
+	}
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"bookclubbot.com/main/models"
+)
+
+// Titles and authors at least this similar after normalization are treated as the same book.
+const DuplicateSimilarityThreshold = 0.85
+
+var isbnPattern = regexp.MustCompile(`(?i)(?:^|[^0-9])((?:97[89][- ]?)?(?:[0-9][- ]?){9}[0-9x])(?:[^0-9]|$)`)
+
+// NormalizeTitle lowercases a title, strips punctuation and leading articles, and moves a
+// trailing article back to the front first, so "Hobbit, The" matches "the hobbit ".
+func NormalizeTitle(title string) string {
+	title = strings.ToLower(strings.TrimSpace(title))
+	for _, article := range []string{"the", "a", "an"} {
+		if strings.HasSuffix(title, ", "+article) {
+			title = article + " " + strings.TrimSuffix(title, ", "+article)
+			break
+		}
+	}
+	words := strings.Fields(stripPunctuation(title))
+	if len(words) > 1 && (words[0] == "the" || words[0] == "a" || words[0] == "an") {
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// NormalizeAuthor lowercases an author and strips punctuation, so "J.R.R. Tolkien"
+// matches "JRR Tolkien".
+func NormalizeAuthor(author string) string {
+	return strings.Join(strings.Fields(stripPunctuation(strings.ToLower(author))), " ")
+}
+
+func stripPunctuation(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) {
+			return r
+		}
+		if r == '-' || r == '_' {
+			return ' '
+		}
+		return -1
+	}, s)
+}
+
+// NormalizeISBN returns the digits of an ISBN-10 or ISBN-13 found in s, or an empty string.
+func NormalizeISBN(s string) string {
+	match := isbnPattern.FindStringSubmatch(s)
+	if match == nil {
+		return ""
+	}
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(match[1]))
+}
+
+// Similarity scores two strings from 0 (nothing in common) to 1 (identical) by edit distance.
+func Similarity(a string, b string) float64 {
+	ar, br := []rune(a), []rune(b)
+	longest := max(len(ar), len(br))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ar, br))/float64(longest)
+}
+
+func levenshtein(a []rune, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// FindDuplicateBook looks for a book already in the pool that matches the given one.
+// Matching ISBNs always match. Otherwise titles must be similar, and so must authors
+// when both are known.
+func FindDuplicateBook(books []models.BookEntry, title string, author string, isbn string) (models.BookEntry, bool) {
+	isbn = NormalizeISBN(isbn)
+	title = NormalizeTitle(title)
+	author = NormalizeAuthor(author)
+	for _, book := range books {
+		if isbn != "" && NormalizeISBN(book.ISBN) == isbn {
+			return book, true
+		}
+		if Similarity(title, NormalizeTitle(book.Name)) < DuplicateSimilarityThreshold {
+			continue
+		}
+		book_author := NormalizeAuthor(book.Author)
+		if author != "" && book_author != "" && Similarity(author, book_author) < DuplicateSimilarityThreshold {
+			continue
+		}
+		return book, true
+	}
+	return models.BookEntry{}, false
+}
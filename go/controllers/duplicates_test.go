@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"testing"
+
+	"bookclubbot.com/main/models"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	for _, title := range []string{"The Hobbit", "the hobbit ", "Hobbit, The", "The Hobbit!"} {
+		if got := NormalizeTitle(title); got != "hobbit" {
+			t.Errorf("NormalizeTitle(%q) = %q, want %q", title, got, "hobbit")
+		}
+	}
+}
+
+func TestNormalizeISBN(t *testing.T) {
+	tests := map[string]string{
+		"978-0-261-10221-7":                                   "9780261102217",
+		"https://openlibrary.org/isbn/026110221X":             "026110221X",
+		"https://www.goodreads.com/book/show/5907.The_Hobbit": "",
+	}
+	for input, want := range tests {
+		if got := NormalizeISBN(input); got != want {
+			t.Errorf("NormalizeISBN(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestFindDuplicateBook_TestCases(t *testing.T) {
+	books := []models.BookEntry{
+		{Id: "hobbit", Name: "The Hobbit", Author: "J.R.R. Tolkien"},
+		{Id: "guide", Name: "The Hitchhiker's Guide to the Galaxy", Author: "Douglas Adams"},
+		{Id: "poems", Name: "Collected Poems", Author: "Sylvia Plath"},
+		{Id: "isbn", Name: "Dune", Author: "Frank Herbert", ISBN: "9780441013593"},
+	}
+	tests := []struct {
+		name   string
+		title  string
+		author string
+		isbn   string
+		wantId string
+	}{
+		{name: "Reordered article", title: "Hobbit, The", author: "JRR Tolkien", wantId: "hobbit"},
+		{name: "Small typo", title: "Hitchiker's Guide to the Galaxy", author: "", wantId: "guide"},
+		{name: "Same title by another author", title: "Collected Poems", author: "Philip Larkin", wantId: ""},
+		{name: "Matching ISBN with another title", title: "Dune (Deluxe Edition)", isbn: "978-0-441-01359-3", wantId: "isbn"},
+		{name: "New book", title: "Piranesi", author: "Susanna Clarke", wantId: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, found := FindDuplicateBook(books, tc.title, tc.author, tc.isbn)
+			if tc.wantId == "" {
+				if found {
+					t.Errorf("Unexpected duplicate %s", got.Id)
+				}
+				return
+			}
+			if !found || got.Id != tc.wantId {
+				t.Errorf("Expected duplicate %s, got %s (found=%v)", tc.wantId, got.Id, found)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"bookclubbot.com/main/models"
+)
+
+// MeetupLength is how long a meetup's scheduled event lasts.
+const MeetupLength = 2 * time.Hour
+
+// Discord limits event locations to 100 characters.
+const maxEventLocationLength = 100
+
+// MeetupEvent is how a meetup appears in Discord's Events tab.
+type MeetupEvent struct {
+	ScheduleId  string
+	Name        string
+	Description string
+	Start       time.Time
+	End         time.Time
+	// Where the meetup happens, for meetups not hosted in a voice channel.
+	Location       string
+	VoiceChannelId string
+}
+
+// Fingerprint changes whenever anything shown in the event changes.
+func (e MeetupEvent) Fingerprint() string {
+	return strings.Join([]string{
+		e.Name, e.Description, e.Start.UTC().Format(time.RFC3339), e.End.UTC().Format(time.RFC3339), e.Location, e.VoiceChannelId,
+	}, "\n")
+}
+
+// ComposeMeetupEvent describes a meetup as a scheduled event. In-person and hybrid meetups
+// are held at their cafe, and virtual ones in their voice channel or at their video link.
+func ComposeMeetupEvent(t *models.ClubTable, meetup models.ScheduleEntry) (MeetupEvent, error) {
+	start, err := MeetupStart(meetup, t.Settings)
+	if err != nil {
+		return MeetupEvent{}, err
+	}
+	event := MeetupEvent{
+		ScheduleId: meetup.Id,
+		Name:       "Book Club",
+		Start:      start,
+		End:        start.Add(MeetupLength),
+	}
+
+	description := []string{"The book is still to be decided."}
+	if book, err := t.GetBookById(meetup.BookId); err == nil {
+		event.Name = fmt.Sprintf("Book Club: %s", book.Name)
+		description = []string{fmt.Sprintf("We're discussing *%s* by %s.", book.Name, book.Author)}
+	}
+
+	switch {
+	case meetup.NeedsCafe():
+		cafe, err := t.GetCafeById(meetup.CafeId)
+		if err != nil {
+			return MeetupEvent{}, err
+		}
+		event.Location = cafe.Name
+		if located := fmt.Sprintf("%s · %s", cafe.Name, cafe.Link); cafe.Link != "" && len(located) <= maxEventLocationLength {
+			event.Location = located
+		}
+		if cafe.Link != "" {
+			description = append(description, fmt.Sprintf("📍 Directions: %s", cafe.Link))
+		}
+	case meetup.VoiceChannelId != "":
+		event.VoiceChannelId = meetup.VoiceChannelId
+	case meetup.VideoLink != "" && len(meetup.VideoLink) <= maxEventLocationLength:
+		event.Location = meetup.VideoLink
+	default:
+		event.Location = "Online"
+	}
+	if meetup.HasOnlineLocation() {
+		description = append(description, fmt.Sprintf("💻 Join online: %s", meetup.OnlineLocation()))
+	}
+	event.Description = strings.Join(description, "\n")
+	return event, nil
+}
+
+type EventSyncAction string
+
+const (
+	EventCreate EventSyncAction = "create"
+	EventUpdate EventSyncAction = "update"
+	EventCancel EventSyncAction = "cancel"
+)
+
+// EventChange is one step needed to make the guild's events match the schedule.
+type EventChange struct {
+	Action     EventSyncAction
+	ScheduleId string
+	// The mirrored event's ID, for updates and cancels.
+	EventId string
+	Event   MeetupEvent
+}
+
+func (c EventChange) String() string {
+	return fmt.Sprintf("%s event for meetup %s", c.Action, c.ScheduleId)
+}
+
+// ForgetStartedEvents stops tracking events for meetups that have started. Discord ends
+// those on its own, so they are never changed or cancelled.
+func ForgetStartedEvents(t *models.ClubTable, now time.Time) {
+	t.ScheduledEvents = slices.DeleteFunc(t.ScheduledEvents, func(e models.ScheduledEventEntry) bool {
+		meetup, err := t.GetScheduleById(e.ScheduleId)
+		if err != nil {
+			return false
+		}
+		start, err := MeetupStart(meetup, t.Settings)
+		return err == nil && !start.After(now)
+	})
+}
+
+// PlanEventSync lists the changes that mirror each upcoming meetup as an event. Events for
+// meetups that left the schedule are cancelled, as are all events when events are turned off.
+// An event that moves between a voice channel and an external location is replaced.
+func PlanEventSync(t *models.ClubTable, now time.Time) []EventChange {
+	changes := []EventChange{}
+	wanted := map[string]bool{}
+	for _, meetup := range t.Schedule {
+		if t.Settings.EventsGuildId == "" {
+			break
+		}
+		event, err := ComposeMeetupEvent(t, meetup)
+		// Meetups that aren't dated or don't have a cafe yet are mirrored once they are planned.
+		if err != nil || !event.Start.After(now) {
+			continue
+		}
+		wanted[meetup.Id] = true
+
+		index := slices.IndexFunc(t.ScheduledEvents, func(e models.ScheduledEventEntry) bool {
+			return e.ScheduleId == meetup.Id
+		})
+		switch {
+		case index < 0:
+			changes = append(changes, EventChange{Action: EventCreate, ScheduleId: meetup.Id, Event: event})
+		case t.ScheduledEvents[index].Voice != (event.VoiceChannelId != ""):
+			changes = append(changes,
+				EventChange{Action: EventCancel, ScheduleId: meetup.Id, EventId: t.ScheduledEvents[index].EventId},
+				EventChange{Action: EventCreate, ScheduleId: meetup.Id, Event: event},
+			)
+		case t.ScheduledEvents[index].Fingerprint != event.Fingerprint():
+			changes = append(changes, EventChange{Action: EventUpdate, ScheduleId: meetup.Id, EventId: t.ScheduledEvents[index].EventId, Event: event})
+		}
+	}
+	for _, e := range t.ScheduledEvents {
+		if !wanted[e.ScheduleId] {
+			changes = append(changes, EventChange{Action: EventCancel, ScheduleId: e.ScheduleId, EventId: e.EventId})
+		}
+	}
+	return changes
+}
+
+// RecordEvent remembers the event mirroring a meetup as it was last sent to Discord.
+func RecordEvent(t *models.ClubTable, eventId string, event MeetupEvent) {
+	entry := models.ScheduledEventEntry{
+		ScheduleId:  event.ScheduleId,
+		EventId:     eventId,
+		Fingerprint: event.Fingerprint(),
+		Voice:       event.VoiceChannelId != "",
+	}
+	for n := range t.ScheduledEvents {
+		if t.ScheduledEvents[n].ScheduleId == event.ScheduleId {
+			t.ScheduledEvents[n] = entry
+			return
+		}
+	}
+	t.ScheduledEvents = append(t.ScheduledEvents, entry)
+}
+
+func ForgetEvent(t *models.ClubTable, scheduleId string) {
+	t.ScheduledEvents = slices.DeleteFunc(t.ScheduledEvents, func(e models.ScheduledEventEntry) bool {
+		return e.ScheduleId == scheduleId
+	})
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"bookclubbot.com/main/models"
+)
+
+func eventsFixture() models.ClubTable {
+	return models.ClubTable{
+		Settings: models.ClubSettings{EventsGuildId: "guild", TimeZone: "UTC"},
+		Schedule: []models.ScheduleEntry{
+			{Id: "past", Date: "October 17, 2026", BookId: "b1", CafeId: "c1"},
+			{Id: "cafe", Date: "October 24, 2026", BookId: "b1", CafeId: "c1"},
+			{Id: "voice", Date: "October 31, 2026", Kind: models.MeetingVirtual, VoiceChannelId: "555"},
+		},
+		BookPool: []models.BookEntry{{Id: "b1", Name: "Piranesi", Author: "Susanna Clarke"}},
+		CafePool: []models.CafeEntry{{Id: "c1", Name: "Bean There", Link: "https://maps.example/bean"}},
+	}
+}
+
+func TestComposeMeetupEvent(t *testing.T) {
+	table := eventsFixture()
+
+	event, err := ComposeMeetupEvent(&table, table.Schedule[1])
+	if err != nil {
+		t.Fatalf("Internal error %v", err)
+	}
+	if event.Name != "Book Club: Piranesi" || event.Location != "Bean There · https://maps.example/bean" {
+		t.Errorf("Unexpected cafe event %+v", event)
+	}
+	if !event.Start.Equal(time.Date(2026, 10, 24, 14, 0, 0, 0, time.UTC)) || event.End.Sub(event.Start) != MeetupLength {
+		t.Errorf("Unexpected event times %v to %v", event.Start, event.End)
+	}
+
+	event, err = ComposeMeetupEvent(&table, table.Schedule[2])
+	if err != nil {
+		t.Fatalf("Internal error %v", err)
+	}
+	if event.VoiceChannelId != "555" || event.Location != "" || !strings.Contains(event.Description, "still to be decided") {
+		t.Errorf("Unexpected voice event %+v", event)
+	}
+}
+
+func TestPlanEventSync(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	table := eventsFixture()
+
+	changes := PlanEventSync(&table, now)
+	if len(changes) != 2 || changes[0].Action != EventCreate || changes[1].Action != EventCreate {
+		t.Fatalf("Expected events to be created for the two upcoming meetups, got %v", changes)
+	}
+	for n, change := range changes {
+		RecordEvent(&table, []string{"e1", "e2"}[n], change.Event)
+	}
+	if changes = PlanEventSync(&table, now); len(changes) != 0 {
+		t.Errorf("Expected no changes once events match the schedule, got %v", changes)
+	}
+
+	table.Schedule[1].Kind = models.MeetingVirtual
+	table.Schedule[1].VoiceChannelId = "777"
+	table.Schedule[2].BookId = "b1"
+	changes = PlanEventSync(&table, now)
+	if len(changes) != 3 ||
+		changes[0].Action != EventCancel || changes[0].EventId != "e1" ||
+		changes[1].Action != EventCreate || changes[1].ScheduleId != "cafe" ||
+		changes[2].Action != EventUpdate || changes[2].EventId != "e2" {
+		t.Errorf("Expected the moved meetup to be replaced and the other updated, got %v", changes)
+	}
+
+	table.Settings.EventsGuildId = ""
+	changes = PlanEventSync(&table, now)
+	if len(changes) != 2 || changes[0].Action != EventCancel || changes[1].Action != EventCancel {
+		t.Errorf("Expected every event to be cancelled when events are turned off, got %v", changes)
+	}
+}
+
+func TestForgetStartedEvents(t *testing.T) {
+	table := eventsFixture()
+	table.ScheduledEvents = []models.ScheduledEventEntry{
+		{ScheduleId: "past", EventId: "e0"},
+		{ScheduleId: "cafe", EventId: "e1"},
+		{ScheduleId: "removed", EventId: "e2"},
+	}
+	ForgetStartedEvents(&table, time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC))
+	if len(table.ScheduledEvents) != 2 || table.ScheduledEvents[0].EventId != "e1" {
+		t.Errorf("Expected only the started meetup's event to be forgotten, got %v", table.ScheduledEvents)
+	}
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"bookclubbot.com/main/models"
+)
+
+// recommenderFairness wraps a strategy so the member whose book was read last can't
+// win again right away, unless nobody else has a book in the running.
+type recommenderFairness struct {
+	SelectionStrategy
+}
+
+func (s recommenderFairness) Select(candidates []models.BookEntry, history []models.BookEntry) (models.BookEntry, error) {
+	if len(history) == 0 || history[len(history)-1].RecommenderId == "" {
+		return s.SelectionStrategy.Select(candidates, history)
+	}
+	last_recommender := history[len(history)-1].RecommenderId
+	others := []models.BookEntry{}
+	for _, book := range candidates {
+		if book.RecommenderId != last_recommender {
+			others = append(others, book)
+		}
+	}
+	if len(others) == 0 {
+		return s.SelectionStrategy.Select(candidates, history)
+	}
+	return s.SelectionStrategy.Select(others, history)
+}
+
+// WithRecommenderFairness applies the club's recommender fairness setting to a strategy.
+func WithRecommenderFairness(strategy SelectionStrategy, settings models.ClubSettings) SelectionStrategy {
+	if !settings.NoBackToBackRecommender {
+		return strategy
+	}
+	return recommenderFairness{SelectionStrategy: strategy}
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"bookclubbot.com/main/models"
+)
+
+func TestWithRecommenderFairness_PreventsBackToBackPicks(t *testing.T) {
+	history := []models.BookEntry{{Id: "read", RecommenderId: "alice"}}
+	candidates := []models.BookEntry{
+		{Id: "alice-again", RecommenderId: "alice", Votes: 10},
+		{Id: "bob", RecommenderId: "bob", Votes: 1},
+	}
+
+	got, err := WithRecommenderFairness(HighestVotes{}, models.ClubSettings{}).Select(candidates, history)
+	if err != nil || got.Id != "alice-again" {
+		t.Errorf("Fairness should be off by default, got %s %v", got.Id, err)
+	}
+
+	fair := WithRecommenderFairness(HighestVotes{}, models.ClubSettings{NoBackToBackRecommender: true})
+	got, err = fair.Select(candidates, history)
+	if err != nil || got.Id != "bob" {
+		t.Errorf("The same recommender won back-to-back, got %s %v", got.Id, err)
+	}
+
+	// With nobody else in the running, the last recommender can still win.
+	got, err = fair.Select(candidates[:1], history)
+	if err != nil || got.Id != "alice-again" {
+		t.Errorf("Expected a fallback to the only recommender, got %s %v", got.Id, err)
+	}
+}
+
+func TestWithRecommenderFairness_AppliesToTheFirstPick(t *testing.T) {
+	books := []models.BookEntry{
+		{Id: "read", Read: true, RecommenderId: "alice", ReadAt: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{Id: "alice-again", RecommenderId: "alice", Votes: 10},
+		{Id: "bob", RecommenderId: "bob", Votes: 1},
+	}
+	// Nothing is on the schedule yet, so the last read book only comes from the reading history.
+	schedules := make([]models.ScheduleEntry, 4)
+
+	fair := WithRecommenderFairness(HighestVotes{}, models.ClubSettings{NoBackToBackRecommender: true})
+	err := AssignBooksToScheduleWithStrategy(fair, books, schedules)
+	if err != nil {
+		t.Fatalf("Internal error %v", err)
+	}
+	if schedules[0].BookId != "bob" {
+		t.Errorf("The same recommender won back-to-back, got %s", schedules[0].BookId)
+	}
+}
+
+func TestWithRecommenderFairness_AppliesToQueuedWinners(t *testing.T) {
+	books := []models.BookEntry{
+		{Id: "read", Read: true, RecommenderId: "alice", ReadAt: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{Id: "alice-winner", RecommenderId: "alice", Queued: true},
+		{Id: "bob", RecommenderId: "bob", Votes: 1},
+	}
+	schedules := make([]models.ScheduleEntry, 4)
+
+	strategy := WithRecommenderFairness(WithQueuedWinners(HighestVotes{}), models.ClubSettings{NoBackToBackRecommender: true})
+	err := AssignBooksToScheduleWithStrategy(strategy, books, schedules)
+	if err != nil {
+		t.Fatalf("Internal error %v", err)
+	}
+	if schedules[0].BookId != "bob" {
+		t.Errorf("A poll winner from the last recommender won back-to-back, got %s", schedules[0].BookId)
+	}
+	if !books[1].Queued {
+		t.Errorf("The poll winner should stay queued for the next pick")
+	}
+}
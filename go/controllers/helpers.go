@@ -2,21 +2,25 @@ package controllers
 
 import (
 	"fmt"
-	"math/rand/v2"
-	"sort"
 
 	"bookclubbot.com/main/models"
 )
 
 // this layer does not mutate the underlying state objects, it operates without side effects.
 func selectNextBook(books []models.BookEntry) (models.BookEntry, error) {
+	return selectNextBookWith(WithQueuedWinners(DefaultSelectionStrategy()), books, nil)
+}
+
+// selectNextBookWith picks an unread book using the given strategy. History holds the
+// books read most recently, oldest first, for strategies that rotate between picks.
+func selectNextBookWith(strategy SelectionStrategy, books []models.BookEntry, history []models.BookEntry) (models.BookEntry, error) {
 	if len(books) == 0 {
 		return models.BookEntry{}, fmt.Errorf("No books provided.")
 	}
 	valid_books := []models.BookEntry{}
 
 	for _, book := range books {
-		if book.Read {
+		if book.Read || book.Archived || book.Vetoed {
 			continue
 		}
 		valid_books = append(valid_books, book)
@@ -26,12 +30,5 @@ func selectNextBook(books []models.BookEntry) (models.BookEntry, error) {
 		return models.BookEntry{}, fmt.Errorf("No valid books provided.")
 	}
 
-	sort.Slice(valid_books, func(i, j int) bool {
-		return valid_books[i].Votes > valid_books[j].Votes
-	})
-
-	upper_bound := min(5, len(valid_books))
-	i := rand.IntN(upper_bound)
-
-	return valid_books[i], nil
+	return strategy.Select(valid_books, history)
 }
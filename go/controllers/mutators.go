@@ -3,6 +3,7 @@ package controllers
 import (
 	"fmt"
 	"math/rand"
+	"slices"
 	"time"
 
 	"bookclubbot.com/main/models"
@@ -60,6 +61,10 @@ func AssignDatesToSchedule(schedules []models.ScheduleEntry) error {
 }
 
 func AssignBooksToSchedule(books []models.BookEntry, schedules []models.ScheduleEntry) error {
+	return AssignBooksToScheduleWithStrategy(WithQueuedWinners(DefaultSelectionStrategy()), books, schedules)
+}
+
+func AssignBooksToScheduleWithStrategy(strategy SelectionStrategy, books []models.BookEntry, schedules []models.ScheduleEntry) error {
 	if len(schedules) < 1 {
 		return nil
 	}
@@ -73,7 +78,7 @@ func AssignBooksToSchedule(books []models.BookEntry, schedules []models.Schedule
 			continue
 		}
 
-		next_book, err := selectNextBook(books)
+		next_book, err := selectNextBookWith(strategy, books, readingHistory(books))
 		if err != nil {
 			return fmt.Errorf("Unable to select next book for our schedule: %w", err)
 		}
@@ -81,6 +86,8 @@ func AssignBooksToSchedule(books []models.BookEntry, schedules []models.Schedule
 		for i, book := range books {
 			if book.Id == next_book.Id {
 				books[i].Read = true
+				books[i].ReadAt = time.Now()
+				books[i].Queued = false
 				found_book = true
 				break
 			}
@@ -102,51 +109,180 @@ func AssignBooksToSchedule(books []models.BookEntry, schedules []models.Schedule
 	return nil
 }
 
+// readingHistory returns the books the club has read, oldest first. Books read before
+// reading dates were recorded come first, in the order they were recommended.
+func readingHistory(books []models.BookEntry) []models.BookEntry {
+	history := []models.BookEntry{}
+	for _, book := range books {
+		if book.Read {
+			history = append(history, book)
+		}
+	}
+	slices.SortStableFunc(history, func(a, b models.BookEntry) int {
+		return a.ReadAt.Compare(b.ReadAt)
+	})
+	return history
+}
+
 func AssignCafesToSchedule(cafes []models.CafeEntry, schedules []models.ScheduleEntry) error {
+	return AssignCafesToScheduleWithHeadcounts(cafes, schedules, nil)
+}
+
+// AssignCafesToScheduleWithHeadcounts picks a cafe that can seat each meetup's expected
+// headcount, and the roomiest cafe when none are big enough. Members who RSVP'd were told
+// where to go, so meetups with RSVPs keep their cafe while it is still in the pool and
+// fits. Other meetups are reassigned like AssignCafesToSchedule does.
+func AssignCafesToScheduleWithHeadcounts(cafes []models.CafeEntry, schedules []models.ScheduleEntry, headcounts map[string]int) error {
 	if len(schedules) < 1 {
 		return nil
 	}
-	if len(cafes) < 1 {
-		return fmt.Errorf("No cafes available to assign to schedule.")
-	}
 
 	for i := range schedules {
-		random_index := rand.Intn(len(cafes))
-		schedules[i].CafeId = cafes[random_index].Id
+		// virtual weeks don't meet at a cafe, so they are skipped in the rotation.
+		if !schedules[i].NeedsCafe() {
+			schedules[i].CafeId = ""
+			continue
+		}
+		if len(cafes) < 1 {
+			return fmt.Errorf("No cafes available to assign to schedule.")
+		}
+		headcount := headcounts[schedules[i].Id]
+		fitting := []models.CafeEntry{}
+		roomiest := cafes[0]
+		for _, cafe := range cafes {
+			if CafeFits(cafe, headcount) {
+				fitting = append(fitting, cafe)
+			}
+			if cafe.Capacity > roomiest.Capacity {
+				roomiest = cafe
+			}
+		}
+		keep := headcount > 0 && slices.ContainsFunc(fitting, func(cafe models.CafeEntry) bool {
+			return cafe.Id == schedules[i].CafeId
+		})
+		switch {
+		case keep:
+		case len(fitting) == 0:
+			schedules[i].CafeId = roomiest.Id
+		default:
+			random_index := rand.Intn(len(fitting))
+			schedules[i].CafeId = fitting[random_index].Id
+		}
 	}
 	return nil
 }
 
-func AddBook(books []models.BookEntry, title string, author string, goodreadsLink string, description string) error {
+func AddBook(books *[]models.BookEntry, title string, author string, goodreadsLink string, description string, genre string, recommenderId string) (models.BookEntry, error) {
 	new_book := models.BookEntry{
-		Id:          models.GenerateId(),
-		Name:        title,
-		Author:      author,
-		Link:        goodreadsLink,
-		Description: description,
-		Votes:       0,
-		Read:        false,
-	}
-	books = append(books, new_book)
-	return nil
+		Id:            models.GenerateId(),
+		Name:          title,
+		Author:        author,
+		Link:          goodreadsLink,
+		Description:   description,
+		ISBN:          NormalizeISBN(goodreadsLink),
+		Genre:         genre,
+		RecommenderId: recommenderId,
+		Votes:         0,
+		Read:          false,
+		CreatedAt:     time.Now(),
+	}
+	*books = append(*books, new_book)
+	return new_book, nil
 }
 
-func AddCafe(cafes []models.CafeEntry, name string, googleMapsLink string) error {
+func AddCafe(cafes *[]models.CafeEntry, name string, googleMapsLink string) error {
 	new_cafe := models.CafeEntry{
 		Id:   models.GenerateId(),
 		Name: name,
 		Link: googleMapsLink,
 	}
-	cafes = append(cafes, new_cafe)
+	*cafes = append(*cafes, new_cafe)
 	return nil
 }
 
-func UpdateVotes(books []models.BookEntry, bookName string, vote_count int) error {
+func UpdateVotes(books []models.BookEntry, bookId string, vote_count int) error {
 	for i, book := range books {
-		if book.Name == bookName {
+		if book.Id == bookId {
 			books[i].Votes = vote_count
 			return nil
 		}
 	}
-	return fmt.Errorf("Book with name '%s' not found", bookName)
+	return fmt.Errorf("Book with ID '%s' not found", bookId)
+}
+
+// UpdateBook changes the details of a recommended book. Catalog metadata is cleared when
+// the title or author changes, since it may describe a different book.
+func UpdateBook(books []models.BookEntry, bookId string, title string, author string, goodreadsLink string, description string, genre string) (models.BookEntry, error) {
+	for i, book := range books {
+		if book.Id != bookId {
+			continue
+		}
+		if book.Name != title || book.Author != author {
+			books[i].BookMetadata = models.BookMetadata{}
+			books[i].ISBN = ""
+		}
+		if isbn := NormalizeISBN(goodreadsLink); isbn != "" {
+			books[i].ISBN = isbn
+		}
+		books[i].Name = title
+		books[i].Author = author
+		books[i].Link = goodreadsLink
+		books[i].Description = description
+		books[i].Genre = genre
+		return books[i], nil
+	}
+	return models.BookEntry{}, fmt.Errorf("Book with ID '%s' not found", bookId)
+}
+
+// WithdrawBook removes a recommendation and every vote and veto cast on it. Books that
+// were read, are on the schedule or are in an open poll can't be withdrawn.
+func WithdrawBook(t *models.ClubTable, bookId string) error {
+	book, err := t.GetBookById(bookId)
+	if err != nil {
+		return err
+	}
+	if book.Read || book.Queued {
+		return fmt.Errorf("'%s' has already been picked for book club.", book.Name)
+	}
+	for _, s := range t.Schedule {
+		if s.BookId == bookId {
+			return fmt.Errorf("'%s' is on the schedule.", book.Name)
+		}
+	}
+	for _, poll := range t.Polls {
+		if !poll.Closed && slices.Contains(poll.Finalists, bookId) {
+			return fmt.Errorf("'%s' is in an open poll.", book.Name)
+		}
+	}
+
+	t.BookPool = slices.DeleteFunc(t.BookPool, func(b models.BookEntry) bool { return b.Id == bookId })
+	t.Votes = slices.DeleteFunc(t.Votes, func(v models.VoteEntry) bool { return v.BookId == bookId })
+	t.Vetoes = slices.DeleteFunc(t.Vetoes, func(v models.VetoEntry) bool { return v.BookId == bookId })
+	return nil
+}
+
+// ApplyBookMetadata stores catalog metadata on a book. An ISBN the member gave is kept.
+func ApplyBookMetadata(books []models.BookEntry, bookId string, metadata models.BookMetadata, isbn string) error {
+	for i, book := range books {
+		if book.Id == bookId {
+			books[i].BookMetadata = metadata
+			if books[i].ISBN == "" {
+				books[i].ISBN = NormalizeISBN(isbn)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("Book with ID '%s' not found", bookId)
+}
+
+// SetBookMessage records which message members vote on for a book.
+func SetBookMessage(books []models.BookEntry, bookId string, channelId string, messageId string) error {
+	for i, book := range books {
+		if book.Id == bookId {
+			books[i].ChannelId = channelId
+			books[i].MessageId = messageId
+			return nil
+		}
+	}
+	return fmt.Errorf("Book with ID '%s' not found", bookId)
 }
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"fmt"
+	"slices"
+
+	"bookclubbot.com/main/models"
+)
+
+// Capabilities lists everything a role can be granted, in the order they are shown to organizers.
+func Capabilities() []models.Capability {
+	return []models.Capability{
+		models.CapabilityRecommend,
+		models.CapabilityVote,
+		models.CapabilityPlanSchedule,
+		models.CapabilityManageCafes,
+		models.CapabilityAdmin,
+	}
+}
+
+// DefaultCapabilities are what every member can do until a guild grants its @everyone role something else.
+func DefaultCapabilities() []models.Capability {
+	return []models.Capability{models.CapabilityRecommend, models.CapabilityVote}
+}
+
+// ParseCapability checks that a name is a known capability.
+func ParseCapability(name string) (models.Capability, error) {
+	capability := models.Capability(name)
+	if !slices.Contains(Capabilities(), capability) {
+		return "", fmt.Errorf("Unknown capability '%s'.", name)
+	}
+	return capability, nil
+}
+
+// MemberCan reports whether a member with the given roles has a capability in a guild.
+// Guild admins can always do everything, so they can't lock themselves out.
+func MemberCan(t *models.ClubTable, guildId string, roleIds []string, isGuildAdmin bool, capability models.Capability) bool {
+	if isGuildAdmin {
+		return true
+	}
+	granted := DefaultCapabilities()
+	// The @everyone role shares its ID with the guild.
+	if grant := findRoleGrant(t, guildId, guildId); grant != nil {
+		granted = grant.Capabilities
+	}
+	for _, roleId := range roleIds {
+		if grant := findRoleGrant(t, guildId, roleId); grant != nil {
+			granted = append(slices.Clone(granted), grant.Capabilities...)
+		}
+	}
+	return slices.Contains(granted, capability) || slices.Contains(granted, models.CapabilityAdmin)
+}
+
+// RoleCapabilities returns what a role has been granted in a guild.
+func RoleCapabilities(t *models.ClubTable, guildId string, roleId string) []models.Capability {
+	if grant := findRoleGrant(t, guildId, roleId); grant != nil {
+		return grant.Capabilities
+	}
+	if roleId == guildId {
+		return DefaultCapabilities()
+	}
+	return nil
+}
+
+// GrantCapability gives everyone with a role a capability.
+func GrantCapability(t *models.ClubTable, guildId string, roleId string, capability models.Capability) error {
+	if slices.Contains(RoleCapabilities(t, guildId, roleId), capability) {
+		return fmt.Errorf("That role already has the %s capability.", capability)
+	}
+	grant := editableRoleGrant(t, guildId, roleId)
+	grant.Capabilities = append(grant.Capabilities, capability)
+	return nil
+}
+
+// RevokeCapability takes a capability away from a role. Members may still have it through another role.
+func RevokeCapability(t *models.ClubTable, guildId string, roleId string, capability models.Capability) error {
+	if !slices.Contains(RoleCapabilities(t, guildId, roleId), capability) {
+		return fmt.Errorf("That role doesn't have the %s capability.", capability)
+	}
+	grant := editableRoleGrant(t, guildId, roleId)
+	grant.Capabilities = slices.DeleteFunc(slices.Clone(grant.Capabilities), func(c models.Capability) bool {
+		return c == capability
+	})
+	return nil
+}
+
+// editableRoleGrant returns a role's grant, adding one that starts from its current capabilities if needed.
+func editableRoleGrant(t *models.ClubTable, guildId string, roleId string) *models.RoleGrantEntry {
+	if grant := findRoleGrant(t, guildId, roleId); grant != nil {
+		return grant
+	}
+	t.RoleGrants = append(t.RoleGrants, models.RoleGrantEntry{
+		GuildId:      guildId,
+		RoleId:       roleId,
+		Capabilities: slices.Clone(RoleCapabilities(t, guildId, roleId)),
+	})
+	return &t.RoleGrants[len(t.RoleGrants)-1]
+}
+
+func findRoleGrant(t *models.ClubTable, guildId string, roleId string) *models.RoleGrantEntry {
+	for n := range t.RoleGrants {
+		if t.RoleGrants[n].GuildId == guildId && t.RoleGrants[n].RoleId == roleId {
+			return &t.RoleGrants[n]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"testing"
+
+	"bookclubbot.com/main/models"
+)
+
+func TestMemberCan_DefaultsAndRoles(t *testing.T) {
+	table := models.ClubTable{}
+	if !MemberCan(&table, "guild", nil, false, models.CapabilityRecommend) {
+		t.Errorf("Expected members to recommend by default")
+	}
+	if MemberCan(&table, "guild", nil, false, models.CapabilityManageCafes) {
+		t.Errorf("Expected members not to manage cafes by default")
+	}
+	if !MemberCan(&table, "guild", nil, true, models.CapabilityAdmin) {
+		t.Errorf("Expected guild admins to have every capability")
+	}
+
+	err := GrantCapability(&table, "guild", "cafe-scouts", models.CapabilityManageCafes)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !MemberCan(&table, "guild", []string{"cafe-scouts"}, false, models.CapabilityManageCafes) {
+		t.Errorf("Expected the granted role to manage cafes")
+	}
+	if MemberCan(&table, "other-guild", []string{"cafe-scouts"}, false, models.CapabilityManageCafes) {
+		t.Errorf("Expected grants to only apply to their own guild")
+	}
+
+	err = GrantCapability(&table, "guild", "admins", models.CapabilityAdmin)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !MemberCan(&table, "guild", []string{"admins"}, false, models.CapabilityPlanSchedule) {
+		t.Errorf("Expected the admin capability to imply every other")
+	}
+}
+
+func TestRevokeCapability_FromEveryone(t *testing.T) {
+	table := models.ClubTable{}
+	err := RevokeCapability(&table, "guild", "guild", models.CapabilityRecommend)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if MemberCan(&table, "guild", nil, false, models.CapabilityRecommend) {
+		t.Errorf("Expected recommending to be revoked from everyone")
+	}
+	if !MemberCan(&table, "guild", nil, false, models.CapabilityVote) {
+		t.Errorf("Expected the other default capabilities to remain")
+	}
+
+	err = RevokeCapability(&table, "guild", "guild", models.CapabilityRecommend)
+	if err == nil {
+		t.Errorf("Expected revoking a missing capability to fail")
+	}
+	err = GrantCapability(&table, "guild", "guild", models.CapabilityVote)
+	if err == nil {
+		t.Errorf("Expected granting a held capability to fail")
+	}
+}
+
+func TestParseCapability(t *testing.T) {
+	_, err := ParseCapability("plan_schedule")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	_, err = ParseCapability("launch_rockets")
+	if err == nil {
+		t.Errorf("Expected an unknown capability to be rejected")
+	}
+}
@@ -0,0 +1,237 @@
+package controllers
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"bookclubbot.com/main/models"
+)
+
+// RunoffRound is one round of instant-runoff tabulation.
+type RunoffRound struct {
+	// First-choice votes per remaining book.
+	Tallies map[string]int
+	// Ballots with no remaining choices.
+	Exhausted  int
+	Eliminated []string
+}
+
+// TopUnreadBooks returns up to n of the most voted books that can still be picked,
+// most voted first.
+func TopUnreadBooks(books []models.BookEntry, n int) []models.BookEntry {
+	unread := []models.BookEntry{}
+	for _, book := range books {
+		if !book.Read && !book.Archived && !book.Vetoed {
+			unread = append(unread, book)
+		}
+	}
+	sort.SliceStable(unread, func(i, j int) bool {
+		return unread[i].Votes > unread[j].Votes
+	})
+	return unread[:min(n, len(unread))]
+}
+
+// StartPoll creates a ranked-choice poll among the given finalists.
+func StartPoll(t *models.ClubTable, finalists []models.BookEntry, deadline time.Time) (models.PollEntry, error) {
+	if len(finalists) < 2 {
+		return models.PollEntry{}, fmt.Errorf("A poll needs at least 2 finalists, found %d.", len(finalists))
+	}
+	poll := models.PollEntry{
+		Id:       models.GenerateId(),
+		Deadline: deadline,
+	}
+	for _, book := range finalists {
+		poll.Finalists = append(poll.Finalists, book.Id)
+	}
+	t.Polls = append(t.Polls, poll)
+	return poll, nil
+}
+
+// SetPollMessage records where a poll was posted.
+func SetPollMessage(t *models.ClubTable, pollId string, channelId string, messageId string) error {
+	for i := range t.Polls {
+		if t.Polls[i].Id == pollId {
+			t.Polls[i].ChannelId = channelId
+			t.Polls[i].MessageId = messageId
+			return nil
+		}
+	}
+	return fmt.Errorf("Poll with ID '%s' not found", pollId)
+}
+
+// DiscardPoll removes a poll that was never posted, so it doesn't hold its finalists.
+func DiscardPoll(t *models.ClubTable, pollId string) {
+	t.Polls = slices.DeleteFunc(t.Polls, func(p models.PollEntry) bool { return p.Id == pollId })
+}
+
+// CastRankedVote sets a member's choice for one rank (0 is first choice) on their ballot.
+func CastRankedVote(t *models.ClubTable, pollId string, userId string, rank int, bookId string, now time.Time) (models.BallotEntry, error) {
+	for i := range t.Polls {
+		poll := &t.Polls[i]
+		if poll.Id != pollId {
+			continue
+		}
+		if poll.Closed || now.After(poll.Deadline) {
+			return models.BallotEntry{}, fmt.Errorf("This poll has closed.")
+		}
+		if rank < 0 || rank >= len(poll.Finalists) {
+			return models.BallotEntry{}, fmt.Errorf("Rank %d is out of range.", rank+1)
+		}
+		found_book := false
+		for _, id := range poll.Finalists {
+			if id == bookId {
+				found_book = true
+				break
+			}
+		}
+		if !found_book {
+			return models.BallotEntry{}, fmt.Errorf("BookId=%s is not a finalist in this poll.", bookId)
+		}
+
+		ballot_index := -1
+		for j, ballot := range poll.Ballots {
+			if ballot.UserId == userId {
+				ballot_index = j
+				break
+			}
+		}
+		if ballot_index == -1 {
+			poll.Ballots = append(poll.Ballots, models.BallotEntry{UserId: userId})
+			ballot_index = len(poll.Ballots) - 1
+		}
+		ballot := &poll.Ballots[ballot_index]
+		for len(ballot.Rankings) <= rank {
+			ballot.Rankings = append(ballot.Rankings, "")
+		}
+		// A book can only hold one rank on a ballot.
+		for j := range ballot.Rankings {
+			if ballot.Rankings[j] == bookId {
+				ballot.Rankings[j] = ""
+			}
+		}
+		ballot.Rankings[rank] = bookId
+		ballot.Timestamp = now
+		return *ballot, nil
+	}
+	return models.BallotEntry{}, fmt.Errorf("Poll with ID '%s' not found", pollId)
+}
+
+// ClosePoll tabulates a poll and queues the winner to be scheduled next.
+func ClosePoll(t *models.ClubTable, pollId string) (string, []RunoffRound, error) {
+	for i := range t.Polls {
+		poll := &t.Polls[i]
+		if poll.Id != pollId {
+			continue
+		}
+		if poll.Closed {
+			return poll.WinnerId, nil, fmt.Errorf("Poll %s is already closed.", pollId)
+		}
+		poll.Closed = true
+
+		ballots := [][]string{}
+		for _, ballot := range poll.Ballots {
+			ballots = append(ballots, ballot.Rankings)
+		}
+		winner, rounds, err := TabulateInstantRunoff(poll.Finalists, ballots)
+		if err != nil {
+			return "", rounds, err
+		}
+		poll.WinnerId = winner
+
+		for j := range t.BookPool {
+			if t.BookPool[j].Id == winner {
+				t.BookPool[j].Queued = true
+			}
+		}
+		return winner, rounds, nil
+	}
+	return "", nil, fmt.Errorf("Poll with ID '%s' not found", pollId)
+}
+
+// queuedWinners wraps a strategy so poll winners still in the running go ahead of any
+// other pick. Wrappers around it, like recommender fairness, can still rule them out.
+type queuedWinners struct {
+	SelectionStrategy
+}
+
+func (s queuedWinners) Select(candidates []models.BookEntry, history []models.BookEntry) (models.BookEntry, error) {
+	queued := []models.BookEntry{}
+	for _, book := range candidates {
+		if book.Queued {
+			queued = append(queued, book)
+		}
+	}
+	if len(queued) == 0 {
+		return s.SelectionStrategy.Select(candidates, history)
+	}
+	return s.SelectionStrategy.Select(queued, history)
+}
+
+// WithQueuedWinners makes a strategy pick queued poll winners first. Apply it before any
+// other wrapper so their rules apply to the winners too.
+func WithQueuedWinners(strategy SelectionStrategy) SelectionStrategy {
+	return queuedWinners{SelectionStrategy: strategy}
+}
+
+// TabulateInstantRunoff finds the winner of a ranked-choice election. Each round the
+// candidate with the fewest first choices is eliminated until one has a majority of the
+// ballots still in play. Ties are broken in favor of the candidate listed first.
+func TabulateInstantRunoff(candidates []string, ballots [][]string) (string, []RunoffRound, error) {
+	if len(candidates) == 0 {
+		return "", nil, fmt.Errorf("No candidates provided.")
+	}
+	if len(ballots) == 0 {
+		return "", nil, fmt.Errorf("No ballots were cast.")
+	}
+
+	remaining := map[string]bool{}
+	for _, c := range candidates {
+		remaining[c] = true
+	}
+	rounds := []RunoffRound{}
+
+	for {
+		round := RunoffRound{Tallies: map[string]int{}}
+		for c := range remaining {
+			round.Tallies[c] = 0
+		}
+		for _, ballot := range ballots {
+			counted := false
+			for _, choice := range ballot {
+				if remaining[choice] {
+					round.Tallies[choice]++
+					counted = true
+					break
+				}
+			}
+			if !counted {
+				round.Exhausted++
+			}
+		}
+
+		active_ballots := len(ballots) - round.Exhausted
+		leader := ""
+		for _, c := range candidates {
+			if remaining[c] && (leader == "" || round.Tallies[c] > round.Tallies[leader]) {
+				leader = c
+			}
+		}
+		if len(remaining) == 1 || round.Tallies[leader]*2 > active_ballots {
+			rounds = append(rounds, round)
+			return leader, rounds, nil
+		}
+
+		// Eliminate the last listed candidate among those with the fewest votes.
+		loser := ""
+		for _, c := range candidates {
+			if remaining[c] && (loser == "" || round.Tallies[c] <= round.Tallies[loser]) {
+				loser = c
+			}
+		}
+		delete(remaining, loser)
+		round.Eliminated = append(round.Eliminated, loser)
+		rounds = append(rounds, round)
+	}
+}
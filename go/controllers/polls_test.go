@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"bookclubbot.com/main/models"
+)
+
+func TestTabulateInstantRunoff_TestCases(t *testing.T) {
+	tests := []struct {
+		name        string
+		candidates  []string
+		ballots     [][]string
+		winner      string
+		rounds      int
+		expectError bool
+	}{
+		{
+			name:       "Majority on first choices wins in one round",
+			candidates: []string{"a", "b", "c"},
+			ballots:    [][]string{{"a"}, {"a", "b"}, {"b"}},
+			winner:     "a",
+			rounds:     1,
+		},
+		{
+			name:       "Eliminated votes transfer to the next choice",
+			candidates: []string{"a", "b", "c"},
+			// a leads on first choices, but c's voters prefer b.
+			ballots: [][]string{{"a"}, {"a"}, {"b"}, {"b"}, {"c", "b"}},
+			winner:  "b",
+			rounds:  2,
+		},
+		{
+			name:       "Exhausted ballots drop out of the majority",
+			candidates: []string{"a", "b", "c"},
+			ballots:    [][]string{{"a"}, {"a"}, {"b"}, {"c"}},
+			winner:     "a",
+			rounds:     2,
+		},
+		{
+			name:       "Ties go to the candidate listed first",
+			candidates: []string{"a", "b"},
+			ballots:    [][]string{{"b"}, {"a"}},
+			winner:     "a",
+			rounds:     2,
+		},
+		{
+			name:        "Error with no ballots",
+			candidates:  []string{"a", "b"},
+			ballots:     [][]string{},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			winner, rounds, err := TabulateInstantRunoff(tc.candidates, tc.ballots)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected an error, but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if winner != tc.winner {
+				t.Errorf("Expected %s to win, got %s", tc.winner, winner)
+			}
+			if len(rounds) != tc.rounds {
+				t.Errorf("Expected %d rounds, got %d", tc.rounds, len(rounds))
+			}
+		})
+	}
+}
+
+func TestPoll_WinnerIsScheduledNext(t *testing.T) {
+	table := models.ClubTable{
+		BookPool: []models.BookEntry{
+			{Id: "1", Votes: 10},
+			{Id: "2", Votes: 5},
+			{Id: "3", Votes: 1},
+		},
+	}
+	now := time.Now()
+	poll, err := StartPoll(&table, TopUnreadBooks(table.BookPool, 2), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Internal Error %v", err)
+	}
+	if len(poll.Finalists) != 2 || poll.Finalists[0] != "1" {
+		t.Errorf("Expected the 2 most voted books as finalists, got %v", poll.Finalists)
+	}
+
+	_, err = CastRankedVote(&table, poll.Id, "alice", 0, "3", now)
+	if err == nil {
+		t.Errorf("A book that isn't a finalist was accepted")
+	}
+	ballot, err := CastRankedVote(&table, poll.Id, "alice", 0, "2", now)
+	if err != nil {
+		t.Fatalf("Internal Error %v", err)
+	}
+	if len(ballot.Rankings) != 1 || ballot.Rankings[0] != "2" {
+		t.Errorf("Unexpected ballot %v", ballot.Rankings)
+	}
+
+	winner, _, err := ClosePoll(&table, poll.Id)
+	if err != nil {
+		t.Fatalf("Internal Error %v", err)
+	}
+	if winner != "2" {
+		t.Errorf("Expected book 2 to win, got %s", winner)
+	}
+	_, err = CastRankedVote(&table, poll.Id, "bob", 0, "1", now)
+	if err == nil {
+		t.Errorf("A vote was accepted after the poll closed")
+	}
+
+	schedules := make([]models.ScheduleEntry, 4)
+	err = AssignBooksToSchedule(table.BookPool, schedules)
+	if err != nil {
+		t.Fatalf("Internal Error %v", err)
+	}
+	if schedules[0].BookId != "2" {
+		t.Errorf("The poll winner was not scheduled next, got %s", schedules[0].BookId)
+	}
+	if table.BookPool[1].Queued {
+		t.Errorf("The poll winner stayed queued after being scheduled")
+	}
+}
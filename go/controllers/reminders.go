@@ -0,0 +1,241 @@
+package controllers
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"bookclubbot.com/main/models"
+)
+
+// ReminderChannel is the recipient recorded for reminders posted to the reminder channel.
+const ReminderChannel = "channel"
+
+// DefaultMeetupTime is when meetups start if the club hasn't said otherwise.
+const DefaultMeetupTime = "14:00"
+
+// DefaultReminderOffsetMinutes sends reminders a day and two hours before each meetup.
+func DefaultReminderOffsetMinutes() []int {
+	return []int{24 * 60, 2 * 60}
+}
+
+// Reminder is a reminder that is due to go out.
+type Reminder struct {
+	Meetup models.ScheduleEntry
+	Start  time.Time
+	// ReminderChannel or the user ID of a subscriber.
+	Recipient string
+	Offset    time.Duration
+}
+
+// MeetupStart returns when a meetup starts, in the club's time zone.
+func MeetupStart(meetup models.ScheduleEntry, settings models.ClubSettings) (time.Time, error) {
+	location, err := ClubLocation(settings)
+	if err != nil {
+		return time.Time{}, err
+	}
+	date, err := time.Parse(TIME_FORMAT, meetup.Date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Unable to parse meetup date '%s': %v", meetup.Date, err)
+	}
+	clock := settings.MeetupTime
+	if clock == "" {
+		clock = DefaultMeetupTime
+	}
+	at, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Unable to parse meetup time '%s': %v", clock, err)
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), at.Hour(), at.Minute(), 0, 0, location), nil
+}
+
+// ParseReminderOffsets reads offsets like "1d, 24h, 2h, 30m" into minutes, longest first.
+func ParseReminderOffsets(text string) ([]int, error) {
+	minutes := []int{}
+	for _, part := range strings.Split(text, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		var offset time.Duration
+		if days, found := strings.CutSuffix(part, "d"); found {
+			n, err := strconv.Atoi(days)
+			if err != nil {
+				return nil, fmt.Errorf("Unable to read '%s'. Use offsets like 1d, 2h or 30m.", part)
+			}
+			offset = time.Duration(n) * 24 * time.Hour
+		} else {
+			var err error
+			offset, err = time.ParseDuration(part)
+			if err != nil {
+				return nil, fmt.Errorf("Unable to read '%s'. Use offsets like 1d, 2h or 30m.", part)
+			}
+		}
+		if offset < time.Minute || offset > 14*24*time.Hour {
+			return nil, fmt.Errorf("Reminders can go out between a minute and two weeks before a meetup, not %s.", part)
+		}
+		if !slices.Contains(minutes, int(offset.Minutes())) {
+			minutes = append(minutes, int(offset.Minutes()))
+		}
+	}
+	if len(minutes) == 0 {
+		return nil, fmt.Errorf("No reminder offsets were given.")
+	}
+	slices.Sort(minutes)
+	slices.Reverse(minutes)
+	return minutes, nil
+}
+
+// FormatReminderOffset describes an offset the way a member would say it, e.g. "2 hours".
+func FormatReminderOffset(offset time.Duration) string {
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s", unit)
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+	switch {
+	case offset%(24*time.Hour) == 0:
+		return plural(int(offset/(24*time.Hour)), "day")
+	case offset%time.Hour == 0:
+		return plural(int(offset/time.Hour), "hour")
+	default:
+		return plural(int(offset/time.Minute), "minute")
+	}
+}
+
+func FormatReminderOffsets(minutes []int) string {
+	described := []string{}
+	for _, m := range minutes {
+		described = append(described, FormatReminderOffset(time.Duration(m)*time.Minute))
+	}
+	return strings.Join(described, ", ")
+}
+
+// TakeDueReminders returns the reminders that have come due and records them as sent.
+// If several of a recipient's reminders for a meetup are due, as after downtime, only the
+// latest is sent. Records for meetups that have started are cleared out.
+func TakeDueReminders(t *models.ClubTable, now time.Time) ([]Reminder, error) {
+	club_offsets := t.Settings.ReminderOffsetMinutes
+	if len(club_offsets) == 0 {
+		club_offsets = DefaultReminderOffsetMinutes()
+	}
+	type recipient struct {
+		id      string
+		offsets []int
+	}
+	// Channel reminders go first, then DMs in the order members subscribed.
+	recipients := []recipient{}
+	if t.Settings.ReminderChannelId != "" {
+		recipients = append(recipients, recipient{ReminderChannel, club_offsets})
+	}
+	for _, subscription := range t.ReminderSubscriptions {
+		offsets := subscription.OffsetMinutes
+		if len(offsets) == 0 {
+			offsets = club_offsets
+		}
+		recipients = append(recipients, recipient{subscription.UserId, offsets})
+	}
+	_, err := ClubLocation(t.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	reminders := []Reminder{}
+	upcoming := map[string]bool{}
+	for _, meetup := range t.Schedule {
+		start, err := MeetupStart(meetup, t.Settings)
+		// Meetups are dated when the schedule is planned, so some may not have a date yet.
+		if err != nil || !start.After(now) {
+			continue
+		}
+		upcoming[meetup.Id] = true
+
+		for _, r := range recipients {
+			latest := -1
+			for _, offset := range r.offsets {
+				due := !now.Before(start.Add(-time.Duration(offset) * time.Minute))
+				if !due || reminderSent(t, meetup.Id, r.id, offset) {
+					continue
+				}
+				t.SentReminders = append(t.SentReminders, models.SentReminderEntry{
+					ScheduleId:    meetup.Id,
+					Recipient:     r.id,
+					OffsetMinutes: offset,
+				})
+				if latest < 0 || offset < latest {
+					latest = offset
+				}
+			}
+			if latest >= 0 {
+				reminders = append(reminders, Reminder{
+					Meetup:    meetup,
+					Start:     start,
+					Recipient: r.id,
+					Offset:    time.Duration(latest) * time.Minute,
+				})
+			}
+		}
+	}
+
+	t.SentReminders = slices.DeleteFunc(t.SentReminders, func(sent models.SentReminderEntry) bool {
+		return !upcoming[sent.ScheduleId]
+	})
+	return reminders, nil
+}
+
+// ForgetReminder removes the records TakeDueReminders made for a reminder that couldn't be
+// delivered, so it is taken again on the next check.
+func ForgetReminder(t *models.ClubTable, reminder Reminder) {
+	t.SentReminders = slices.DeleteFunc(t.SentReminders, func(sent models.SentReminderEntry) bool {
+		return sent.ScheduleId == reminder.Meetup.Id && sent.Recipient == reminder.Recipient &&
+			time.Duration(sent.OffsetMinutes)*time.Minute >= reminder.Offset
+	})
+}
+
+func reminderSent(t *models.ClubTable, scheduleId string, recipient string, offset int) bool {
+	return slices.ContainsFunc(t.SentReminders, func(sent models.SentReminderEntry) bool {
+		return sent.ScheduleId == scheduleId && sent.Recipient == recipient && sent.OffsetMinutes == offset
+	})
+}
+
+// SubscribeToReminders turns DM reminders on or off for a member.
+func SubscribeToReminders(t *models.ClubTable, userId string, enabled bool) error {
+	index := slices.IndexFunc(t.ReminderSubscriptions, func(s models.ReminderSubscriptionEntry) bool {
+		return s.UserId == userId
+	})
+	switch {
+	case enabled && index >= 0:
+		return fmt.Errorf("You already get reminders by DM.")
+	case enabled:
+		t.ReminderSubscriptions = append(t.ReminderSubscriptions, models.ReminderSubscriptionEntry{UserId: userId})
+	case index < 0:
+		return fmt.Errorf("You don't get reminders by DM.")
+	default:
+		t.ReminderSubscriptions = slices.Delete(t.ReminderSubscriptions, index, index+1)
+	}
+	return nil
+}
+
+// SetMemberReminderOffsets chooses when a member's DM reminders go out, subscribing them if needed.
+func SetMemberReminderOffsets(t *models.ClubTable, userId string, minutes []int) {
+	for n := range t.ReminderSubscriptions {
+		if t.ReminderSubscriptions[n].UserId == userId {
+			t.ReminderSubscriptions[n].OffsetMinutes = minutes
+			return
+		}
+	}
+	t.ReminderSubscriptions = append(t.ReminderSubscriptions, models.ReminderSubscriptionEntry{UserId: userId, OffsetMinutes: minutes})
+}
+
+// SetMeetupTime chooses the local time meetups start, such as "14:00".
+func SetMeetupTime(settings *models.ClubSettings, clock string) error {
+	_, err := time.Parse("15:04", clock)
+	if err != nil {
+		return fmt.Errorf("Times look like 14:00, not '%s'.", clock)
+	}
+	settings.MeetupTime = clock
+	return nil
+}
@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"bookclubbot.com/main/models"
+)
+
+func reminderTable() models.ClubTable {
+	return models.ClubTable{
+		Schedule: []models.ScheduleEntry{
+			{Id: "s1", Date: "October 17, 2026"},
+			{Id: "s2", Date: "October 24, 2026"},
+		},
+		Settings: models.ClubSettings{TimeZone: "UTC", ReminderChannelId: "reminders"},
+		ReminderSubscriptions: []models.ReminderSubscriptionEntry{
+			{UserId: "alice"},
+			{UserId: "bob", OffsetMinutes: []int{30}},
+		},
+	}
+}
+
+func TestTakeDueReminders_SendsEachOffsetOnce(t *testing.T) {
+	table := reminderTable()
+
+	// A day before the 2pm meetup.
+	reminders, err := TakeDueReminders(&table, time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(reminders) != 2 || reminders[0].Recipient != ReminderChannel || reminders[1].Recipient != "alice" {
+		t.Fatalf("Expected the channel and alice to be reminded, got %+v", reminders)
+	}
+	if reminders[0].Offset != 24*time.Hour || reminders[0].Meetup.Id != "s1" {
+		t.Errorf("Unexpected reminder %+v", reminders[0])
+	}
+
+	reminders, _ = TakeDueReminders(&table, time.Date(2026, 10, 16, 14, 1, 0, 0, time.UTC))
+	if len(reminders) != 0 {
+		t.Errorf("Expected reminders not to repeat, got %+v", reminders)
+	}
+
+	reminders, _ = TakeDueReminders(&table, time.Date(2026, 10, 17, 13, 30, 0, 0, time.UTC))
+	recipients := []string{}
+	for _, r := range reminders {
+		recipients = append(recipients, r.Recipient)
+	}
+	if !slices.Equal(recipients, []string{ReminderChannel, "alice", "bob"}) {
+		t.Errorf("Expected everyone to get their last reminder, got %v", recipients)
+	}
+}
+
+func TestTakeDueReminders_CollapsesMissedReminders(t *testing.T) {
+	table := reminderTable()
+	table.ReminderSubscriptions = nil
+
+	// The bot was down for both the one day and two hour reminders.
+	reminders, err := TakeDueReminders(&table, time.Date(2026, 10, 17, 13, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(reminders) != 1 || reminders[0].Offset != 2*time.Hour {
+		t.Errorf("Expected only the two hour reminder, got %+v", reminders)
+	}
+	if len(table.SentReminders) != 2 {
+		t.Errorf("Expected both reminders to be recorded, got %+v", table.SentReminders)
+	}
+
+	// Once the meetup starts its records are cleared.
+	TakeDueReminders(&table, time.Date(2026, 10, 17, 14, 0, 0, 0, time.UTC))
+	for _, sent := range table.SentReminders {
+		if sent.ScheduleId == "s1" {
+			t.Errorf("Expected records for the past meetup to be cleared, got %+v", sent)
+		}
+	}
+}
+
+func TestForgetReminder_RetriesFailedReminders(t *testing.T) {
+	table := reminderTable()
+	table.ReminderSubscriptions = nil
+	now := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+
+	reminders, err := TakeDueReminders(&table, now)
+	if err != nil || len(reminders) != 1 {
+		t.Fatalf("Expected one reminder, got %+v %v", reminders, err)
+	}
+	ForgetReminder(&table, reminders[0])
+
+	retried, _ := TakeDueReminders(&table, now.Add(time.Minute))
+	if len(retried) != 1 || retried[0].Offset != reminders[0].Offset {
+		t.Errorf("Expected the failed reminder to be taken again, got %+v", retried)
+	}
+}
+
+func TestTakeDueReminders_UsesMeetupTimeAndZone(t *testing.T) {
+	table := reminderTable()
+	table.ReminderSubscriptions = nil
+	table.Settings.TimeZone = "America/Chicago"
+	table.Settings.MeetupTime = "10:00"
+	table.Settings.ReminderOffsetMinutes = []int{60}
+	if _, err := ClubLocation(table.Settings); err != nil {
+		t.Skip("Time zone data is unavailable")
+	}
+
+	// 10am in Chicago is 3pm UTC during daylight saving time.
+	reminders, _ := TakeDueReminders(&table, time.Date(2026, 10, 17, 13, 59, 0, 0, time.UTC))
+	if len(reminders) != 0 {
+		t.Errorf("Expected no reminder yet, got %+v", reminders)
+	}
+	reminders, _ = TakeDueReminders(&table, time.Date(2026, 10, 17, 14, 0, 0, 0, time.UTC))
+	if len(reminders) != 1 {
+		t.Errorf("Expected the one hour reminder, got %+v", reminders)
+	}
+}
+
+func TestParseReminderOffsets(t *testing.T) {
+	minutes, err := ParseReminderOffsets("2h, 1d, 30m, 24h")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !slices.Equal(minutes, []int{1440, 120, 30}) {
+		t.Errorf("Unexpected offsets %v", minutes)
+	}
+	if FormatReminderOffsets(minutes) != "1 day, 2 hours, 30 minutes" {
+		t.Errorf("Unexpected description %q", FormatReminderOffsets(minutes))
+	}
+	for _, text := range []string{"", "soon", "3w", "15d", "30s"} {
+		if _, err := ParseReminderOffsets(text); err == nil {
+			t.Errorf("Expected %q to be rejected", text)
+		}
+	}
+}
+
+func TestSubscribeToReminders(t *testing.T) {
+	table := models.ClubTable{}
+	if err := SubscribeToReminders(&table, "alice", true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := SubscribeToReminders(&table, "alice", true); err == nil {
+		t.Errorf("Expected subscribing twice to fail")
+	}
+	if err := SubscribeToReminders(&table, "alice", false); err != nil || len(table.ReminderSubscriptions) != 0 {
+		t.Errorf("Expected alice to be unsubscribed, got %v %+v", err, table.ReminderSubscriptions)
+	}
+	SetMemberReminderOffsets(&table, "bob", []int{60})
+	if len(table.ReminderSubscriptions) != 1 || table.ReminderSubscriptions[0].OffsetMinutes[0] != 60 {
+		t.Errorf("Expected choosing offsets to subscribe bob, got %+v", table.ReminderSubscriptions)
+	}
+}
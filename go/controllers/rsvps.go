@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"fmt"
+	"slices"
+
+	"bookclubbot.com/main/models"
+)
+
+func ParseRSVPStatus(status string) (models.RSVPStatus, error) {
+	switch models.RSVPStatus(status) {
+	case models.RSVPGoing, models.RSVPMaybe, models.RSVPNo:
+		return models.RSVPStatus(status), nil
+	}
+	return "", fmt.Errorf("Unknown RSVP '%s'.", status)
+}
+
+// SetRSVP records a member's answer for a meetup, replacing any earlier answer.
+func SetRSVP(t *models.ClubTable, scheduleId string, userId string, status models.RSVPStatus) error {
+	_, err := t.GetScheduleById(scheduleId)
+	if err != nil {
+		return fmt.Errorf("That meetup is no longer on the schedule.")
+	}
+	for n := range t.RSVPs {
+		if t.RSVPs[n].ScheduleId == scheduleId && t.RSVPs[n].UserId == userId {
+			t.RSVPs[n].Status = status
+			return nil
+		}
+	}
+	t.RSVPs = append(t.RSVPs, models.RSVPEntry{ScheduleId: scheduleId, UserId: userId, Status: status})
+	return nil
+}
+
+// PruneRSVPs drops answers for meetups that are no longer on the schedule.
+func PruneRSVPs(t *models.ClubTable) {
+	t.RSVPs = slices.DeleteFunc(t.RSVPs, func(r models.RSVPEntry) bool {
+		_, err := t.GetScheduleById(r.ScheduleId)
+		return err != nil
+	})
+}
+
+// ExpectedHeadcounts is how many members may come to each meetup, counting maybes,
+// keyed by schedule ID.
+func ExpectedHeadcounts(t *models.ClubTable) map[string]int {
+	headcounts := map[string]int{}
+	for _, s := range t.Schedule {
+		going, maybe := t.Headcount(s.Id)
+		headcounts[s.Id] = going + maybe
+	}
+	return headcounts
+}
+
+// CafeFits reports whether a cafe can seat a headcount. Cafes with an unknown capacity always fit.
+func CafeFits(cafe models.CafeEntry, headcount int) bool {
+	return cafe.Capacity == 0 || cafe.Capacity >= headcount
+}
+
+func SetCafeCapacity(cafes []models.CafeEntry, cafeId string, capacity int) error {
+	if capacity < 0 {
+		return fmt.Errorf("Capacity can't be negative.")
+	}
+	for i := range cafes {
+		if cafes[i].Id == cafeId {
+			cafes[i].Capacity = capacity
+			return nil
+		}
+	}
+	return fmt.Errorf("No cafe with ID %s", cafeId)
+}
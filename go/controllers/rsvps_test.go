@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"testing"
+
+	"bookclubbot.com/main/models"
+)
+
+func TestSetRSVP_ReplacesEarlierAnswer(t *testing.T) {
+	table := models.ClubTable{Schedule: []models.ScheduleEntry{{Id: "s1"}}}
+
+	if err := SetRSVP(&table, "s1", "alice", models.RSVPMaybe); err != nil {
+		t.Fatalf("Internal error %v", err)
+	}
+	if err := SetRSVP(&table, "s1", "alice", models.RSVPGoing); err != nil {
+		t.Fatalf("Internal error %v", err)
+	}
+	if len(table.RSVPs) != 1 || table.RSVPs[0].Status != models.RSVPGoing {
+		t.Errorf("Expected one answer of going, got %v", table.RSVPs)
+	}
+	if err := SetRSVP(&table, "gone", "alice", models.RSVPGoing); err == nil {
+		t.Errorf("Expected an error for a meetup that isn't scheduled")
+	}
+}
+
+func TestPruneRSVPs_DropsUnscheduledMeetups(t *testing.T) {
+	table := models.ClubTable{
+		Schedule: []models.ScheduleEntry{{Id: "s2"}},
+		RSVPs: []models.RSVPEntry{
+			{ScheduleId: "s1", UserId: "alice", Status: models.RSVPGoing},
+			{ScheduleId: "s2", UserId: "alice", Status: models.RSVPGoing},
+		},
+	}
+	PruneRSVPs(&table)
+	if len(table.RSVPs) != 1 || table.RSVPs[0].ScheduleId != "s2" {
+		t.Errorf("Expected only the scheduled meetup's RSVP to remain, got %v", table.RSVPs)
+	}
+}
+
+func TestAssignCafesToScheduleWithHeadcounts(t *testing.T) {
+	cafes := []models.CafeEntry{
+		{Id: "tiny", Capacity: 2},
+		{Id: "big", Capacity: 10},
+		{Id: "medium", Capacity: 6},
+	}
+	schedules := []models.ScheduleEntry{
+		{Id: "s1", CafeId: "tiny"},
+		{Id: "s2", CafeId: "medium"},
+		{Id: "s3"},
+	}
+	headcounts := map[string]int{"s1": 8, "s2": 5, "s3": 20}
+
+	err := AssignCafesToScheduleWithHeadcounts(cafes, schedules, headcounts)
+	if err != nil {
+		t.Fatalf("Internal error %v", err)
+	}
+	if schedules[0].CafeId != "big" {
+		t.Errorf("Expected the only cafe that fits 8, got %s", schedules[0].CafeId)
+	}
+	if schedules[1].CafeId != "medium" {
+		t.Errorf("Expected a meetup with RSVPs to keep a cafe that still fits, got %s", schedules[1].CafeId)
+	}
+	if schedules[2].CafeId != "big" {
+		t.Errorf("Expected the roomiest cafe when none fit, got %s", schedules[2].CafeId)
+	}
+}
+
+func TestAssignCafesToScheduleWithHeadcounts_OnlyKeepsCafesForMeetupsWithRSVPs(t *testing.T) {
+	cafes := []models.CafeEntry{{Id: "c1"}, {Id: "c2"}, {Id: "c3"}}
+	schedules := []models.ScheduleEntry{
+		{Id: "s1", CafeId: "c2"},
+		{Id: "s2", CafeId: "c2"},
+		{Id: "s3", CafeId: "closed"},
+	}
+	headcounts := map[string]int{"s1": 3, "s3": 3}
+
+	reassigned := false
+	for range 50 {
+		schedules[1].CafeId = "c2"
+		err := AssignCafesToScheduleWithHeadcounts(cafes, schedules, headcounts)
+		if err != nil {
+			t.Fatalf("Internal error %v", err)
+		}
+		if schedules[0].CafeId != "c2" {
+			t.Fatalf("Expected a meetup with RSVPs to keep its cafe, got %s", schedules[0].CafeId)
+		}
+		reassigned = reassigned || schedules[1].CafeId != "c2"
+	}
+	if !reassigned {
+		t.Errorf("Expected a meetup without RSVPs to be reassigned like /schedule always has")
+	}
+	if schedules[2].CafeId == "closed" {
+		t.Errorf("Expected a cafe that left the pool to be replaced")
+	}
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"slices"
 	"testing"
+	"time"
 
 	"bookclubbot.com/main/models"
 )
@@ -340,3 +341,104 @@ func TestAssignBooksToSchedule_PartialSchedule(t *testing.T) {
 		}
 	}
 }
+
+func TestAssignCafesToSchedule_SkipsVirtualWeeks(t *testing.T) {
+	cafes := []models.CafeEntry{{Id: "cafe-1"}}
+	schedules := []models.ScheduleEntry{
+		{Id: "s1"},
+		{Id: "s2", Kind: models.MeetingVirtual, CafeId: "cafe-old"},
+		{Id: "s3", Kind: models.MeetingHybrid},
+	}
+	err := AssignCafesToSchedule(cafes, schedules)
+	if err != nil {
+		t.Errorf("Internal Error %v", err)
+	}
+	if schedules[0].CafeId != "cafe-1" || schedules[2].CafeId != "cafe-1" {
+		t.Errorf("In-person and hybrid weeks should be assigned a cafe")
+	}
+	if schedules[1].CafeId != "" {
+		t.Errorf("A virtual week was assigned a cafe")
+	}
+
+	// An all-virtual schedule doesn't need any cafes.
+	err = AssignCafesToSchedule(nil, schedules[1:2])
+	if err != nil {
+		t.Errorf("Virtual weeks should not require cafes: %v", err)
+	}
+}
+
+func TestAssignBooksToSchedule_RotatesAgainstEarlierReads(t *testing.T) {
+	books := []models.BookEntry{
+		{Id: "old", Read: true, RecommenderId: "alice", ReadAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Id: "recent", Read: true, RecommenderId: "bob", ReadAt: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{Id: "bobs-next", RecommenderId: "bob", Votes: 10},
+		{Id: "alices-next", RecommenderId: "alice", Votes: 1},
+	}
+	schedules := make([]models.ScheduleEntry, 4)
+
+	err := AssignBooksToScheduleWithStrategy(RecommenderRoundRobin{}, books, schedules)
+	if err != nil {
+		t.Fatalf("Internal Error %v", err)
+	}
+	// Alice has gone longest without a pick, even though nothing is on the schedule yet.
+	if schedules[0].BookId != "alices-next" {
+		t.Errorf("Expected alice's book to be picked, got %s", schedules[0].BookId)
+	}
+	if books[3].ReadAt.IsZero() {
+		t.Errorf("Expected the picked book's reading date to be recorded")
+	}
+}
+
+func TestAddCafe_AppendsToPool(t *testing.T) {
+	cafes := []models.CafeEntry{}
+	err := AddCafe(&cafes, "Hot Java", "https://maps.example.com/hot-java")
+	if err != nil {
+		t.Fatalf("Internal Error %v", err)
+	}
+	if len(cafes) != 1 || cafes[0].Name != "Hot Java" || cafes[0].Id == "" {
+		t.Errorf("Expected the cafe to be added, got %v", cafes)
+	}
+}
+
+func TestUpdateBook_ClearsMetadataWhenTitleChanges(t *testing.T) {
+	books := []models.BookEntry{{
+		Id:           "1",
+		Name:         "The Hobit",
+		Author:       "J.R.R. Tolkien",
+		ISBN:         "9780261102217",
+		BookMetadata: models.BookMetadata{PageCount: 310},
+	}}
+	book, err := UpdateBook(books, "1", "The Hobbit", "J.R.R. Tolkien", "", "", "Fantasy")
+	if err != nil {
+		t.Fatalf("Internal Error %v", err)
+	}
+	if book.Name != "The Hobbit" || books[0].Genre != "Fantasy" {
+		t.Errorf("Book was not updated: %+v", books[0])
+	}
+	if book.PageCount != 0 || book.ISBN != "" {
+		t.Errorf("Stale catalog metadata was kept: %+v", book)
+	}
+}
+
+func TestWithdrawBook(t *testing.T) {
+	table := models.ClubTable{
+		BookPool: []models.BookEntry{{Id: "1"}, {Id: "2"}, {Id: "3", Read: true}},
+		Schedule: []models.ScheduleEntry{{Id: "s1", BookId: "2"}},
+		Votes:    []models.VoteEntry{{UserId: "alice", BookId: "1"}, {UserId: "alice", BookId: "2"}},
+	}
+	if err := WithdrawBook(&table, "2"); err == nil {
+		t.Errorf("A scheduled book was withdrawn")
+	}
+	if err := WithdrawBook(&table, "3"); err == nil {
+		t.Errorf("A read book was withdrawn")
+	}
+	if err := WithdrawBook(&table, "1"); err != nil {
+		t.Fatalf("Internal Error %v", err)
+	}
+	if _, err := table.GetBookById("1"); err == nil {
+		t.Errorf("The withdrawn book is still in the pool")
+	}
+	if len(table.Votes) != 1 || table.Votes[0].BookId != "2" {
+		t.Errorf("Votes for the withdrawn book were kept: %v", table.Votes)
+	}
+}
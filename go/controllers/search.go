@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"sort"
+	"strings"
+)
+
+// Typo-tolerant matches below this similarity are left out of search results.
+const minFuzzySimilarity = 0.6
+
+// FuzzySearch ranks names against a query and returns the indices of up to limit matches,
+// best first. Prefix matches rank above substring matches, which rank above close
+// spellings. An empty query matches everything in its original order.
+func FuzzySearch(query string, names []string, limit int) []int {
+	query = NormalizeAuthor(query)
+	type match struct {
+		index int
+		score float64
+	}
+	matches := []match{}
+	for i, name := range names {
+		score := fuzzyScore(query, NormalizeAuthor(name))
+		if score > 0 {
+			matches = append(matches, match{index: i, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	indices := []int{}
+	for _, m := range matches[:min(limit, len(matches))] {
+		indices = append(indices, m.index)
+	}
+	return indices
+}
+
+func fuzzyScore(query string, name string) float64 {
+	if query == "" {
+		return 1
+	}
+	if strings.HasPrefix(name, query) {
+		return 3
+	}
+	if strings.Contains(name, query) {
+		return 2
+	}
+	// Compare against each word and the start of the name so partial typing still matches.
+	best := 0.0
+	candidates := append(strings.Fields(name), name)
+	for _, candidate := range candidates {
+		runes := []rune(candidate)
+		prefix := string(runes[:min(len(runes), len([]rune(query)))])
+		best = max(best, Similarity(query, prefix))
+	}
+	if best < minFuzzySimilarity {
+		return 0
+	}
+	return best
+}
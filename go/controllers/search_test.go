@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFuzzySearch_TestCases(t *testing.T) {
+	names := []string{"The Hobbit", "Dune", "The Left Hand of Darkness", "Hot Java"}
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{name: "Empty query lists everything", query: "", want: []int{0, 1, 2, 3}},
+		{name: "Prefix ranks first", query: "the", want: []int{0, 2}},
+		{name: "Substring matches", query: "darkness", want: []int{2}},
+		{name: "Typos still match", query: "hobitt", want: []int{0}},
+		{name: "Case and punctuation are ignored", query: "HOT-JAVA", want: []int{3}},
+		{name: "Unrelated queries match nothing", query: "zzzz", want: []int{}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := FuzzySearch(tc.query, names, 25)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("FuzzySearch(%q) = %v, want %v", tc.query, got, tc.want)
+			}
+		})
+	}
+
+	if got := FuzzySearch("", names, 2); len(got) != 2 {
+		t.Errorf("Expected results to be limited to 2, got %v", got)
+	}
+}
@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+
+	"bookclubbot.com/main/models"
+)
+
+// SelectionStrategy picks the next book to read. Candidates are never empty and only
+// hold unread books. History holds recently read books, oldest first.
+type SelectionStrategy interface {
+	Name() string
+	Description() string
+	Select(candidates []models.BookEntry, history []models.BookEntry) (models.BookEntry, error)
+}
+
+// HighestVotes always picks the most voted book. Ties go to the book recommended first.
+type HighestVotes struct{}
+
+func (HighestVotes) Name() string        { return "highest-votes" }
+func (HighestVotes) Description() string { return "The most voted book wins" }
+
+func (HighestVotes) Select(candidates []models.BookEntry, history []models.BookEntry) (models.BookEntry, error) {
+	best := candidates[0]
+	for _, book := range candidates[1:] {
+		if votesOf(book) > votesOf(best) {
+			best = book
+		}
+	}
+	return best, nil
+}
+
+// WeightedLottery draws a book with probability proportional to its votes. Every book
+// gets one extra ticket so books without votes still have a chance.
+type WeightedLottery struct{}
+
+func (WeightedLottery) Name() string        { return "weighted-lottery" }
+func (WeightedLottery) Description() string { return "A lottery where each vote is a ticket" }
+
+func (WeightedLottery) Select(candidates []models.BookEntry, history []models.BookEntry) (models.BookEntry, error) {
+	total := 0.0
+	for _, book := range candidates {
+		total += max(votesOf(book), 0) + 1
+	}
+	ticket := rand.Float64() * total
+	for _, book := range candidates {
+		ticket -= max(votesOf(book), 0) + 1
+		if ticket < 0 {
+			return book, nil
+		}
+	}
+	return candidates[len(candidates)-1], nil
+}
+
+// TopKUniform picks uniformly at random among the K most voted books.
+type TopKUniform struct {
+	K int
+}
+
+func (TopKUniform) Name() string { return "top-k" }
+func (s TopKUniform) Description() string {
+	return fmt.Sprintf("A random pick among the top %d books", s.K)
+}
+
+func (s TopKUniform) Select(candidates []models.BookEntry, history []models.BookEntry) (models.BookEntry, error) {
+	sorted := sortedByVotes(candidates)
+	upper_bound := min(s.K, len(sorted))
+	i := rand.IntN(upper_bound)
+	return sorted[i], nil
+}
+
+// RecommenderRoundRobin picks the most voted book from whoever has gone longest without
+// one of their recommendations being read.
+type RecommenderRoundRobin struct{}
+
+func (RecommenderRoundRobin) Name() string { return "recommender-round-robin" }
+func (RecommenderRoundRobin) Description() string {
+	return "Takes turns between recommenders, most voted book first"
+}
+
+func (RecommenderRoundRobin) Select(candidates []models.BookEntry, history []models.BookEntry) (models.BookEntry, error) {
+	return rotateBy(func(b models.BookEntry) string { return b.RecommenderId }, candidates, history), nil
+}
+
+// GenreRotation picks the most voted book from the genre read least recently.
+type GenreRotation struct{}
+
+func (GenreRotation) Name() string        { return "genre-rotation" }
+func (GenreRotation) Description() string { return "Rotates between genres, most voted book first" }
+
+func (GenreRotation) Select(candidates []models.BookEntry, history []models.BookEntry) (models.BookEntry, error) {
+	return rotateBy(func(b models.BookEntry) string { return b.Genre }, candidates, history), nil
+}
+
+// rotateBy groups candidates by key and returns the most voted book from the group that
+// appears least recently in history. Groups that never appear come first.
+func rotateBy(key func(models.BookEntry) string, candidates []models.BookEntry, history []models.BookEntry) models.BookEntry {
+	last_read := map[string]int{}
+	for i, book := range history {
+		last_read[key(book)] = i + 1
+	}
+	best := models.BookEntry{}
+	found := false
+	for _, book := range sortedByVotes(candidates) {
+		if !found || last_read[key(book)] < last_read[key(best)] {
+			best = book
+			found = true
+		}
+	}
+	return best
+}
+
+// votesOf is how much a book's votes count when picking. With vote aging on, votes are
+// weighted by age rather than counted.
+func votesOf(book models.BookEntry) float64 {
+	if book.AgedVotes != nil {
+		return *book.AgedVotes
+	}
+	return float64(book.Votes)
+}
+
+func sortedByVotes(books []models.BookEntry) []models.BookEntry {
+	sorted := append([]models.BookEntry{}, books...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return votesOf(sorted[i]) > votesOf(sorted[j])
+	})
+	return sorted
+}
+
+// SelectionStrategies lists every strategy a club can choose from.
+func SelectionStrategies() []SelectionStrategy {
+	return []SelectionStrategy{
+		DefaultSelectionStrategy(),
+		HighestVotes{},
+		WeightedLottery{},
+		RecommenderRoundRobin{},
+		GenreRotation{},
+	}
+}
+
+func DefaultSelectionStrategy() SelectionStrategy {
+	return TopKUniform{K: 5}
+}
+
+// GetSelectionStrategy looks up a strategy by name. An empty name gives the default.
+func GetSelectionStrategy(name string) (SelectionStrategy, error) {
+	if name == "" {
+		return DefaultSelectionStrategy(), nil
+	}
+	for _, strategy := range SelectionStrategies() {
+		if strategy.Name() == name {
+			return strategy, nil
+		}
+	}
+	return nil, fmt.Errorf("No selection strategy named '%s'", name)
+}
+
+// SetSelectionStrategy changes the strategy a club uses to pick its next book.
+func SetSelectionStrategy(settings *models.ClubSettings, name string) error {
+	if _, err := GetSelectionStrategy(name); err != nil {
+		return err
+	}
+	settings.SelectionStrategy = name
+	return nil
+}
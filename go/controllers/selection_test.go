@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"testing/quick"
+
+	"bookclubbot.com/main/models"
+)
+
+// makeCandidates builds unread books from generated inputs. Recommenders and genres are
+// drawn from small pools so books share them.
+func makeCandidates(votes []uint8, groups []uint8) []models.BookEntry {
+	books := []models.BookEntry{}
+	for i, v := range votes {
+		group := 0
+		if i < len(groups) {
+			group = int(groups[i] % 4)
+		}
+		books = append(books, models.BookEntry{
+			Id:            fmt.Sprintf("book-%d", i),
+			Votes:         int(v),
+			RecommenderId: fmt.Sprintf("member-%d", group),
+			Genre:         fmt.Sprintf("genre-%d", group),
+		})
+	}
+	return books
+}
+
+func makeHistory(candidates []models.BookEntry, picks []uint8) []models.BookEntry {
+	history := []models.BookEntry{}
+	for _, p := range picks {
+		history = append(history, candidates[int(p)%len(candidates)])
+	}
+	return history
+}
+
+func checkProperty(t *testing.T, property func(candidates []models.BookEntry, history []models.BookEntry, got models.BookEntry) bool, strategy SelectionStrategy) {
+	err := quick.Check(func(votes []uint8, groups []uint8, picks []uint8) bool {
+		if len(votes) == 0 {
+			return true
+		}
+		candidates := makeCandidates(votes, groups)
+		history := makeHistory(candidates, picks)
+		got, err := strategy.Select(candidates, history)
+		if err != nil {
+			return false
+		}
+		in_candidates := slices.ContainsFunc(candidates, func(b models.BookEntry) bool { return b.Id == got.Id })
+		return in_candidates && property(candidates, history, got)
+	}, nil)
+	if err != nil {
+		t.Errorf("%s: %v", strategy.Name(), err)
+	}
+}
+
+func TestSelectionStrategies_PickACandidate(t *testing.T) {
+	for _, strategy := range SelectionStrategies() {
+		checkProperty(t, func(candidates []models.BookEntry, history []models.BookEntry, got models.BookEntry) bool {
+			return true
+		}, strategy)
+	}
+}
+
+func TestHighestVotes_PicksTheMaximum(t *testing.T) {
+	checkProperty(t, func(candidates []models.BookEntry, history []models.BookEntry, got models.BookEntry) bool {
+		for _, book := range candidates {
+			if book.Votes > got.Votes {
+				return false
+			}
+		}
+		return true
+	}, HighestVotes{})
+}
+
+func TestTopKUniform_PicksFromTopK(t *testing.T) {
+	checkProperty(t, func(candidates []models.BookEntry, history []models.BookEntry, got models.BookEntry) bool {
+		better := 0
+		for _, book := range candidates {
+			if book.Votes > got.Votes {
+				better++
+			}
+		}
+		return better < 5
+	}, TopKUniform{K: 5})
+}
+
+func TestWeightedLottery_FavorsVotes(t *testing.T) {
+	candidates := []models.BookEntry{{Id: "popular", Votes: 99}, {Id: "unpopular", Votes: 0}}
+	picks := map[string]int{}
+	for range 1000 {
+		got, err := WeightedLottery{}.Select(candidates, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		picks[got.Id]++
+	}
+	// Expected picks are 990 to 10.
+	if picks["popular"] < 900 {
+		t.Errorf("The lottery did not favor votes: %v", picks)
+	}
+}
+
+// rotationProperty checks that the pick's group hasn't been read more recently than any
+// other candidate group, and that it is the most voted book in its group.
+func rotationProperty(key func(models.BookEntry) string) func(candidates []models.BookEntry, history []models.BookEntry, got models.BookEntry) bool {
+	return func(candidates []models.BookEntry, history []models.BookEntry, got models.BookEntry) bool {
+		last_read := map[string]int{}
+		for i, book := range history {
+			last_read[key(book)] = i + 1
+		}
+		for _, book := range candidates {
+			if last_read[key(book)] < last_read[key(got)] {
+				return false
+			}
+			if key(book) == key(got) && book.Votes > got.Votes {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func TestRecommenderRoundRobin_RotatesRecommenders(t *testing.T) {
+	checkProperty(t, rotationProperty(func(b models.BookEntry) string { return b.RecommenderId }), RecommenderRoundRobin{})
+}
+
+func TestGenreRotation_RotatesGenres(t *testing.T) {
+	checkProperty(t, rotationProperty(func(b models.BookEntry) string { return b.Genre }), GenreRotation{})
+}
+
+func TestGetSelectionStrategy(t *testing.T) {
+	strategy, err := GetSelectionStrategy("")
+	if err != nil || strategy.Name() != DefaultSelectionStrategy().Name() {
+		t.Errorf("An empty name should give the default strategy, got %v %v", strategy, err)
+	}
+	settings := models.ClubSettings{}
+	if err := SetSelectionStrategy(&settings, "not-a-strategy"); err == nil {
+		t.Errorf("Expected an error for an unknown strategy")
+	}
+	if err := SetSelectionStrategy(&settings, "genre-rotation"); err != nil || settings.SelectionStrategy != "genre-rotation" {
+		t.Errorf("Strategy was not set: %v", err)
+	}
+}
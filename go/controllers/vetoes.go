@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"bookclubbot.com/main/models"
+)
+
+var ErrNoVetoesLeft = errors.New("No vetoes left this season")
+
+// SeasonOf names the season a time falls in. Seasons are calendar quarters, e.g. "2026-Q1".
+func SeasonOf(now time.Time) string {
+	return fmt.Sprintf("%d-Q%d", now.Year(), (int(now.Month())-1)/3+1)
+}
+
+// vetoVoter hashes a member and season with the club's salt, so vetoes can be counted per
+// member without saying who cast them.
+func vetoVoter(t *models.ClubTable, userId string, season string) string {
+	sum := sha256.Sum256([]byte(t.VetoSalt + ":" + userId + ":" + season))
+	return hex.EncodeToString(sum[:])
+}
+
+// VetoesLeft returns how many vetoes a member can still cast this season.
+func VetoesLeft(t *models.ClubTable, userId string, now time.Time) int {
+	used := 0
+	season := SeasonOf(now)
+	voter := vetoVoter(t, userId, season)
+	for _, v := range t.Vetoes {
+		if v.Voter == voter && v.Season == season {
+			used++
+		}
+	}
+	return max(t.Settings.VetoesPerSeason-used, 0)
+}
+
+// CastVeto records an anonymous veto against a book and re-applies the veto threshold.
+func CastVeto(t *models.ClubTable, userId string, bookId string, now time.Time) error {
+	if t.Settings.VetoesPerSeason <= 0 {
+		return fmt.Errorf("Vetoes are turned off for this club.")
+	}
+	book, err := t.GetBookById(bookId)
+	if err != nil {
+		return err
+	}
+	if book.Read {
+		return fmt.Errorf("'%s' has already been read.", book.Name)
+	}
+	if t.VetoSalt == "" {
+		t.VetoSalt = models.GenerateId()
+	}
+	season := SeasonOf(now)
+	voter := vetoVoter(t, userId, season)
+	for _, v := range t.Vetoes {
+		if v.Voter == voter && v.Season == season && v.BookId == bookId {
+			return fmt.Errorf("You already vetoed '%s' this season.", book.Name)
+		}
+	}
+	if VetoesLeft(t, userId, now) == 0 {
+		return fmt.Errorf("%w: members get %d per season", ErrNoVetoesLeft, t.Settings.VetoesPerSeason)
+	}
+	t.Vetoes = append(t.Vetoes, models.VetoEntry{
+		Voter:     voter,
+		BookId:    bookId,
+		Season:    season,
+		Timestamp: now,
+	})
+	ApplyVetoes(t, now)
+	return nil
+}
+
+// ApplyVetoes marks the books whose vetoes this season reach the club's threshold.
+func ApplyVetoes(t *models.ClubTable, now time.Time) {
+	counts := VetoCounts(t, now)
+	for i, book := range t.BookPool {
+		t.BookPool[i].Vetoed = t.Settings.VetoThreshold > 0 && counts[book.Id] >= t.Settings.VetoThreshold
+	}
+}
+
+// VetoCounts returns the number of vetoes per book ID cast this season.
+func VetoCounts(t *models.ClubTable, now time.Time) map[string]int {
+	counts := map[string]int{}
+	season := SeasonOf(now)
+	for _, v := range t.Vetoes {
+		if v.Season == season {
+			counts[v.BookId]++
+		}
+	}
+	return counts
+}
+
+// SetVetoRules changes how many vetoes members get and how many it takes to exclude a book.
+func SetVetoRules(t *models.ClubTable, vetoesPerSeason int, threshold int, now time.Time) error {
+	if vetoesPerSeason < 0 || threshold < 0 {
+		return fmt.Errorf("Veto settings can't be negative.")
+	}
+	t.Settings.VetoesPerSeason = vetoesPerSeason
+	t.Settings.VetoThreshold = threshold
+	ApplyVetoes(t, now)
+	return nil
+}
+
+// AddContentWarning attaches a content warning to a book. Repeated warnings are ignored.
+func AddContentWarning(books []models.BookEntry, bookId string, warning string) error {
+	warning = strings.ToLower(strings.TrimSpace(warning))
+	if warning == "" {
+		return fmt.Errorf("Content warning can't be empty.")
+	}
+	for i, book := range books {
+		if book.Id == bookId {
+			if !slices.Contains(book.ContentWarnings, warning) {
+				books[i].ContentWarnings = append(books[i].ContentWarnings, warning)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("Book with ID '%s' not found", bookId)
+}
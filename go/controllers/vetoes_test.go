@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"bookclubbot.com/main/models"
+)
+
+func TestSeasonOf(t *testing.T) {
+	if got := SeasonOf(time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)); got != "2026-Q1" {
+		t.Errorf("Expected 2026-Q1, got %s", got)
+	}
+	if got := SeasonOf(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)); got != "2026-Q4" {
+		t.Errorf("Expected 2026-Q4, got %s", got)
+	}
+}
+
+func TestCastVeto_ExcludesBooksPastThreshold(t *testing.T) {
+	now := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	table := models.ClubTable{
+		BookPool: []models.BookEntry{{Id: "book-1", Votes: 10}, {Id: "book-2"}},
+		Settings: models.ClubSettings{VetoesPerSeason: 1, VetoThreshold: 2},
+	}
+
+	if err := CastVeto(&table, "alice", "book-1", now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if table.BookPool[0].Vetoed {
+		t.Errorf("A book was excluded before reaching the threshold")
+	}
+	err := CastVeto(&table, "alice", "book-2", now)
+	if !errors.Is(err, ErrNoVetoesLeft) {
+		t.Errorf("Expected the season limit to be enforced, got %v", err)
+	}
+	if err := CastVeto(&table, "bob", "book-1", now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !table.BookPool[0].Vetoed {
+		t.Errorf("A book past the threshold was not excluded")
+	}
+
+	for range 10 {
+		got, err := HighestVotes{}.Select(TopUnreadBooks(table.BookPool, 5), nil)
+		if err != nil || got.Id != "book-2" {
+			t.Errorf("A vetoed book could still be selected: %v %v", got.Id, err)
+		}
+		got, err = selectNextBook(table.BookPool)
+		if err != nil || got.Id != "book-2" {
+			t.Errorf("A vetoed book could still be selected: %v %v", got.Id, err)
+		}
+	}
+
+	// Vetoes are restored next season.
+	if VetoesLeft(&table, "alice", now.AddDate(0, 3, 0)) != 1 {
+		t.Errorf("Vetoes did not reset for the new season")
+	}
+
+	// Raising the threshold lets the book back in.
+	if err := SetVetoRules(&table, 1, 3, now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if table.BookPool[0].Vetoed {
+		t.Errorf("The book stayed excluded after the threshold was raised")
+	}
+}
+
+func TestCastVeto_DoesNotStoreTheVoter(t *testing.T) {
+	now := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	table := models.ClubTable{
+		BookPool: []models.BookEntry{{Id: "book-1"}},
+		Settings: models.ClubSettings{VetoesPerSeason: 1, VetoThreshold: 2},
+	}
+
+	if err := CastVeto(&table, "alice", "book-1", now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if table.VetoSalt == "" {
+		t.Errorf("Expected a salt to be generated")
+	}
+	if strings.Contains(table.Vetoes[0].Voter, "alice") {
+		t.Errorf("The veto names its voter: %v", table.Vetoes[0].Voter)
+	}
+	if err := CastVeto(&table, "alice", "book-1", now); err == nil {
+		t.Errorf("Expected an error for a repeated veto")
+	}
+}
+
+func TestApplyVetoes_OnlyCountsThisSeason(t *testing.T) {
+	now := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	table := models.ClubTable{
+		BookPool: []models.BookEntry{{Id: "book-1"}},
+		Settings: models.ClubSettings{VetoesPerSeason: 1, VetoThreshold: 1},
+	}
+
+	if err := CastVeto(&table, "alice", "book-1", now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !table.BookPool[0].Vetoed {
+		t.Fatalf("A book past the threshold was not excluded")
+	}
+
+	next_season := now.AddDate(0, 3, 0)
+	ApplyVetoes(&table, next_season)
+	if table.BookPool[0].Vetoed {
+		t.Errorf("Vetoes from last season still excluded the book")
+	}
+	if counts := VetoCounts(&table, next_season); counts["book-1"] != 0 {
+		t.Errorf("Expected no vetoes this season, got %d", counts["book-1"])
+	}
+}
+
+func TestAddContentWarning(t *testing.T) {
+	books := []models.BookEntry{{Id: "book-1"}}
+	for _, warning := range []string{"Violence", " violence "} {
+		if err := AddContentWarning(books, "book-1", warning); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if len(books[0].ContentWarnings) != 1 || books[0].ContentWarnings[0] != "violence" {
+		t.Errorf("Expected one normalized warning, got %v", books[0].ContentWarnings)
+	}
+	if err := AddContentWarning(books, "book-1", "  "); err == nil {
+		t.Errorf("Expected an error for an empty warning")
+	}
+}
@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"bookclubbot.com/main/models"
+)
+
+var ErrVoteCapReached = errors.New("Vote limit reached")
+
+// AddVote records a member's vote for a book. Voting twice for the same book is a no-op.
+func AddVote(t *models.ClubTable, userId string, bookId string, now time.Time) error {
+	if _, err := t.GetBookById(bookId); err != nil {
+		return err
+	}
+	for _, v := range t.GetVotesByUser(userId) {
+		if v.BookId == bookId {
+			return nil
+		}
+	}
+	if atVoteCap(t, userId) {
+		return fmt.Errorf("%w: members can vote for %d books at a time", ErrVoteCapReached, t.Settings.MaxVotesPerMember)
+	}
+	t.Votes = append(t.Votes, models.VoteEntry{
+		UserId:    userId,
+		BookId:    bookId,
+		Timestamp: now,
+	})
+	return recountBookVotes(t, bookId)
+}
+
+// atVoteCap reports whether a member has used up their votes. Only votes for books still
+// up for a vote count, so votes for books that were read, archived or vetoed free up.
+func atVoteCap(t *models.ClubTable, userId string) bool {
+	if t.Settings.MaxVotesPerMember <= 0 {
+		return false
+	}
+	open_votes := 0
+	for _, v := range t.GetVotesByUser(userId) {
+		book, err := t.GetBookById(v.BookId)
+		if err == nil && !book.Read && !book.Archived && !book.Vetoed {
+			open_votes++
+		}
+	}
+	return open_votes >= t.Settings.MaxVotesPerMember
+}
+
+// SetVoteLimit changes how many books a member can vote for at once. Zero means no limit.
+func SetVoteLimit(settings *models.ClubSettings, maxVotes int) error {
+	if maxVotes < 0 {
+		return fmt.Errorf("The vote limit can't be negative.")
+	}
+	settings.MaxVotesPerMember = maxVotes
+	return nil
+}
+
+// RemoveVote takes back a member's vote for a book. Removing a vote that doesn't exist is a no-op.
+func RemoveVote(t *models.ClubTable, userId string, bookId string) error {
+	if _, err := t.GetBookById(bookId); err != nil {
+		return err
+	}
+	kept := t.Votes[:0]
+	for _, v := range t.Votes {
+		if v.UserId == userId && v.BookId == bookId {
+			continue
+		}
+		kept = append(kept, v)
+	}
+	t.Votes = kept
+	return recountBookVotes(t, bookId)
+}
+
+// SyncBookVotes makes the vote ledger for a book match the members currently voting for it.
+// Members who already voted keep their original timestamp. New votes from members who are
+// at the club's vote cap are not recorded and their IDs are returned.
+func SyncBookVotes(t *models.ClubTable, bookId string, voterIds []string, now time.Time) ([]string, error) {
+	if _, err := t.GetBookById(bookId); err != nil {
+		return nil, err
+	}
+
+	current_voters := make(map[string]bool, len(voterIds))
+	for _, id := range voterIds {
+		current_voters[id] = true
+	}
+
+	// Drop votes from members who no longer vote for the book.
+	kept := t.Votes[:0]
+	already_voted := map[string]bool{}
+	for _, v := range t.Votes {
+		if v.BookId == bookId {
+			if !current_voters[v.UserId] {
+				continue
+			}
+			already_voted[v.UserId] = true
+		}
+		kept = append(kept, v)
+	}
+	t.Votes = kept
+
+	rejected := []string{}
+	for _, id := range voterIds {
+		if already_voted[id] {
+			continue
+		}
+		if atVoteCap(t, id) {
+			rejected = append(rejected, id)
+			continue
+		}
+		t.Votes = append(t.Votes, models.VoteEntry{
+			UserId:    id,
+			BookId:    bookId,
+			Timestamp: now,
+		})
+		already_voted[id] = true
+	}
+
+	return rejected, recountBookVotes(t, bookId)
+}
+
+// RemoveMemberVotes drops every vote cast by a member, e.g. after they leave the server,
+// and recounts the books they had voted for.
+func RemoveMemberVotes(t *models.ClubTable, userId string) error {
+	affected_books := map[string]bool{}
+	kept := t.Votes[:0]
+	for _, v := range t.Votes {
+		if v.UserId == userId {
+			affected_books[v.BookId] = true
+			continue
+		}
+		kept = append(kept, v)
+	}
+	t.Votes = kept
+
+	for bookId := range affected_books {
+		err := recountBookVotes(t, bookId)
+		if err != nil {
+			return fmt.Errorf("Unable to recount votes after removing member %s: %w", userId, err)
+		}
+	}
+	return nil
+}
+
+func recountBookVotes(t *models.ClubTable, bookId string) error {
+	vote_count := 0
+	for _, v := range t.Votes {
+		if v.BookId == bookId {
+			vote_count++
+		}
+	}
+	return UpdateVotes(t.BookPool, bookId, vote_count)
+}
@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"bookclubbot.com/main/models"
+)
+
+func TestSyncBookVotes_DerivesVotesFromLedger(t *testing.T) {
+	first_vote := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	table := models.ClubTable{
+		BookPool: []models.BookEntry{{Id: "book-1", Votes: 1}},
+		Votes:    []models.VoteEntry{{UserId: "alice", BookId: "book-1", Timestamp: first_vote}},
+	}
+
+	now := first_vote.AddDate(0, 0, 7)
+	rejected, err := SyncBookVotes(&table, "book-1", []string{"alice", "bob"}, now)
+	if err != nil {
+		t.Fatalf("Internal Error %v", err)
+	}
+	if len(rejected) != 0 {
+		t.Errorf("No votes should be rejected without a cap, got %v", rejected)
+	}
+	if table.BookPool[0].Votes != 2 {
+		t.Errorf("Expected 2 votes, got %d", table.BookPool[0].Votes)
+	}
+	for _, v := range table.Votes {
+		if v.UserId == "alice" && !v.Timestamp.Equal(first_vote) {
+			t.Errorf("An existing vote lost its original timestamp")
+		}
+	}
+
+	// Alice takes her vote back.
+	_, err = SyncBookVotes(&table, "book-1", []string{"bob"}, now)
+	if err != nil {
+		t.Fatalf("Internal Error %v", err)
+	}
+	if table.BookPool[0].Votes != 1 || len(table.GetVotesByUser("alice")) != 0 {
+		t.Errorf("A removed vote was still counted")
+	}
+}
+
+func TestSyncBookVotes_EnforcesVoteCap(t *testing.T) {
+	table := models.ClubTable{
+		BookPool: []models.BookEntry{{Id: "book-1"}, {Id: "book-2"}},
+		Votes:    []models.VoteEntry{{UserId: "alice", BookId: "book-1"}},
+		Settings: models.ClubSettings{MaxVotesPerMember: 1},
+	}
+	rejected, err := SyncBookVotes(&table, "book-2", []string{"alice", "bob"}, time.Now())
+	if err != nil {
+		t.Fatalf("Internal Error %v", err)
+	}
+	if len(rejected) != 1 || rejected[0] != "alice" {
+		t.Errorf("Expected alice's vote to be rejected, got %v", rejected)
+	}
+	if table.BookPool[1].Votes != 1 {
+		t.Errorf("Expected 1 vote for book-2, got %d", table.BookPool[1].Votes)
+	}
+}
+
+func TestRemoveMemberVotes(t *testing.T) {
+	table := models.ClubTable{
+		BookPool: []models.BookEntry{{Id: "book-1", Votes: 2}, {Id: "book-2", Votes: 1}},
+		Votes: []models.VoteEntry{
+			{UserId: "alice", BookId: "book-1"},
+			{UserId: "bob", BookId: "book-1"},
+			{UserId: "alice", BookId: "book-2"},
+		},
+	}
+	err := RemoveMemberVotes(&table, "alice")
+	if err != nil {
+		t.Fatalf("Internal Error %v", err)
+	}
+	if table.BookPool[0].Votes != 1 || table.BookPool[1].Votes != 0 {
+		t.Errorf("Votes were not recounted after the member left: %+v", table.BookPool)
+	}
+	if len(table.Votes) != 1 {
+		t.Errorf("Expected 1 vote left in the ledger, got %d", len(table.Votes))
+	}
+}
+
+func TestAddAndRemoveVote(t *testing.T) {
+	table := models.ClubTable{
+		BookPool: []models.BookEntry{{Id: "book-1"}, {Id: "book-2"}},
+		Settings: models.ClubSettings{MaxVotesPerMember: 1},
+	}
+	if err := AddVote(&table, "alice", "book-1", time.Now()); err != nil {
+		t.Fatalf("Internal Error %v", err)
+	}
+	// Voting twice doesn't double count.
+	if err := AddVote(&table, "alice", "book-1", time.Now()); err != nil {
+		t.Fatalf("Internal Error %v", err)
+	}
+	if table.BookPool[0].Votes != 1 {
+		t.Errorf("Expected 1 vote, got %d", table.BookPool[0].Votes)
+	}
+	err := AddVote(&table, "alice", "book-2", time.Now())
+	if !errors.Is(err, ErrVoteCapReached) {
+		t.Errorf("Expected the vote cap to be enforced, got %v", err)
+	}
+	if err := RemoveVote(&table, "alice", "book-1"); err != nil {
+		t.Fatalf("Internal Error %v", err)
+	}
+	if table.BookPool[0].Votes != 0 {
+		t.Errorf("Expected 0 votes after unvoting, got %d", table.BookPool[0].Votes)
+	}
+}
+
+func TestAddVote_OnlyOpenBooksCountTowardTheCap(t *testing.T) {
+	table := models.ClubTable{
+		BookPool: []models.BookEntry{
+			{Id: "read", Read: true},
+			{Id: "archived", Archived: true},
+			{Id: "vetoed", Vetoed: true},
+			{Id: "open"},
+			{Id: "next"},
+		},
+		Votes: []models.VoteEntry{
+			{UserId: "alice", BookId: "read"},
+			{UserId: "alice", BookId: "archived"},
+			{UserId: "alice", BookId: "vetoed"},
+		},
+		Settings: models.ClubSettings{MaxVotesPerMember: 1},
+	}
+
+	err := AddVote(&table, "alice", "open", time.Now())
+	if err != nil {
+		t.Fatalf("Expected votes for closed books not to count, got %v", err)
+	}
+	err = AddVote(&table, "alice", "next", time.Now())
+	if !errors.Is(err, ErrVoteCapReached) {
+		t.Errorf("Expected the cap to apply to open books, got %v", err)
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -9,10 +11,15 @@ import (
 
 	"github.com/bwmarrin/discordgo"
 
+	"bookclubbot.com/main/services"
 	"bookclubbot.com/main/views"
 )
 
 func main() {
+	dev_guild := flag.String("dev-guild", "", "Register commands to this guild only, where they update instantly, and remove the global ones")
+	dry_run := flag.Bool("dry-run", false, "Print the command changes that would be made and exit")
+	flag.Parse()
+
 	if os.Getenv("DISCORD_BOT_TOKEN") == "" {
 		log.Fatalf("DISCORD_BOT_TOKEN environment variable not set")
 	}
@@ -23,13 +30,33 @@ func main() {
 
 	dg.Identify.Intents = discordgo.IntentsGuilds |
 		discordgo.IntentsGuildMessages |
-		discordgo.IntentsGuildMessageReactions
+		discordgo.IntentsGuildMessageReactions |
+		discordgo.IntentsGuildMembers
+
+	if *dry_run {
+		app, err := dg.User("@me")
+		if err != nil {
+			log.Fatalf("Unable to look up the bot user: %v", err)
+		}
+		changes, err := views.SyncCommands(dg, app.ID, *dev_guild, true)
+		if err != nil {
+			log.Fatalf("Unable to plan command sync: %v", err)
+		}
+		for _, change := range changes {
+			fmt.Println(change)
+		}
+		fmt.Printf("%d command changes planned.\n", len(changes))
+		return
+	}
 
 	dg.AddHandler(func(s *discordgo.Session, r *discordgo.Ready) {
 		log.Println("Bot is up!")
 	})
 
 	dg.AddHandler(views.HandleVotingReactions)
+	dg.AddHandler(views.HandleVotingReactionRemove)
+	dg.AddHandler(views.HandleVotingReactionRemoveAll)
+	dg.AddHandler(views.HandleMemberLeave)
 
 	// Open the connection
 	err = dg.Open()
@@ -38,17 +65,24 @@ func main() {
 	}
 	defer dg.Close()
 
+	views.SetBookEnricher(services.NewOpenLibraryClient(os.Getenv("OPEN_LIBRARY_BASE_URL"), os.Getenv("OPEN_LIBRARY_COVERS_URL")))
 	views.RegisterInteractionCreateHandler(dg)
+	_, err = views.SyncCommands(dg, dg.State.User.ID, *dev_guild, false)
+	if err != nil {
+		log.Fatalf("Error syncing slash commands: %v", err)
+	}
+	views.ResumeBookPolls(dg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go views.RunScheduledJobs(ctx, dg)
 
 	fmt.Println("Bot is now running. Press CTRL-C to exit.")
 
-	// 4. Graceful Shutdown (to optionally delete commands on exit)
+	// 4. Graceful Shutdown
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 	<-stop
 
-	// Optional: Delete command on exit to keep test environment clean
-	// dg.ApplicationCommandDelete(dg.State.User.ID, "", registeredCmd.ID)
-
 	fmt.Println("Gracefully shutting down...")
 }
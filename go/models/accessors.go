@@ -1,6 +1,9 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 func (t *ClubTable) GetCafeById(id string) (CafeEntry, error) {
 	for _, c := range t.CafePool {
@@ -28,3 +31,116 @@ func (t *ClubTable) GetBookById(id string) (BookEntry, error) {
 	}
 	return BookEntry{}, fmt.Errorf("No book with ID %s", id)
 }
+
+// GetBookByTitle finds a book by title, ignoring case and surrounding whitespace.
+func (t *ClubTable) GetBookByTitle(title string) (BookEntry, error) {
+	for _, b := range t.BookPool {
+		if strings.EqualFold(strings.TrimSpace(b.Name), strings.TrimSpace(title)) {
+			return b, nil
+		}
+	}
+	return BookEntry{}, fmt.Errorf("No book titled '%s'", title)
+}
+
+// GetBookByIdOrTitle finds a book from a command option, which holds a book ID when the
+// member picked an autocomplete suggestion and a title when they typed their own.
+func (t *ClubTable) GetBookByIdOrTitle(value string) (BookEntry, error) {
+	if book, err := t.GetBookById(value); err == nil {
+		return book, nil
+	}
+	return t.GetBookByTitle(value)
+}
+
+func (t *ClubTable) GetBookByMessageId(messageId string) (BookEntry, error) {
+	for _, b := range t.BookPool {
+		if b.MessageId != "" && b.MessageId == messageId {
+			return b, nil
+		}
+	}
+	return BookEntry{}, fmt.Errorf("No book with message ID %s", messageId)
+}
+
+func (t *ClubTable) GetPollById(id string) (PollEntry, error) {
+	for _, p := range t.Polls {
+		if p.Id == id {
+			return p, nil
+		}
+	}
+	return PollEntry{}, fmt.Errorf("No poll with ID %s", id)
+}
+
+func (t *ClubTable) GetVotesByUser(userId string) []VoteEntry {
+	votes := []VoteEntry{}
+	for _, v := range t.Votes {
+		if v.UserId == userId {
+			votes = append(votes, v)
+		}
+	}
+	return votes
+}
+
+// GetRSVPs returns the members' answers for a meetup, in the order they were given.
+func (t *ClubTable) GetRSVPs(scheduleId string) []RSVPEntry {
+	rsvps := []RSVPEntry{}
+	for _, r := range t.RSVPs {
+		if r.ScheduleId == scheduleId {
+			rsvps = append(rsvps, r)
+		}
+	}
+	return rsvps
+}
+
+// Headcount returns how many members are going to a meetup and how many might.
+func (t *ClubTable) Headcount(scheduleId string) (going int, maybe int) {
+	for _, r := range t.GetRSVPs(scheduleId) {
+		switch r.Status {
+		case RSVPGoing:
+			going++
+		case RSVPMaybe:
+			maybe++
+		}
+	}
+	return going, maybe
+}
+
+// Details summarizes a book's catalog metadata, e.g. "310 pages, 1937", or returns an
+// empty string when nothing is known.
+func (b BookEntry) Details() string {
+	details := []string{}
+	if b.PageCount > 0 {
+		details = append(details, fmt.Sprintf("%d pages", b.PageCount))
+	}
+	if b.PublishYear > 0 {
+		details = append(details, fmt.Sprintf("%d", b.PublishYear))
+	}
+	return strings.Join(details, ", ")
+}
+
+// NeedsCafe reports whether the meetup happens at a cafe.
+func (s ScheduleEntry) NeedsCafe() bool {
+	return s.Kind != MeetingVirtual
+}
+
+// HasOnlineLocation reports whether members can join the meetup online.
+func (s ScheduleEntry) HasOnlineLocation() bool {
+	return s.Kind == MeetingVirtual || s.Kind == MeetingHybrid
+}
+
+// OnlineLocation returns a markdown description of where to join online, or
+// an empty string when the meetup has no online component.
+func (s ScheduleEntry) OnlineLocation() string {
+	if !s.HasOnlineLocation() {
+		return ""
+	}
+	locations := []string{}
+	if s.VoiceChannelId != "" {
+		locations = append(locations, fmt.Sprintf("<#%s>", s.VoiceChannelId))
+	}
+	if s.VideoLink != "" {
+		locations = append(locations, fmt.Sprintf("[Video Call](%s)", s.VideoLink))
+	}
+	if len(locations) == 0 {
+		return "TBD"
+	}
+	return strings.Join(locations, " or ")
+}
@@ -1,35 +1,45 @@
 package models
 
 import (
+	_ "embed"
 	"fmt"
-	"os"
 	"strings"
 	"text/template"
 )
 
+//go:embed templates/schedule.template.md
+var scheduleTemplate string
+
+type renderedScheduleEntry struct {
+	Date           string
+	Link           string
+	CafeName       string
+	BookName       string
+	InPerson       bool
+	OnlineLocation string
+	Going          int
+	Maybe          int
+}
+
 func (t *ClubTable) RenderSchedule() (string, error) {
 
-	readFileContents, err := os.ReadFile("models/templates/schedule.template.md")
+	tmpl, err := template.New("schedule_template").Parse(scheduleTemplate)
 	if err != nil {
-		return "", fmt.Errorf("Error opening schedule.template.md: %v", err)
+		return "", fmt.Errorf("Error parsing schedule.template.md: %v", err)
 	}
 
-	tmpl, err := template.New("schedule_template").Parse(string(readFileContents))
-
 	var buf strings.Builder
 
 	var rendered_schedule_data = struct {
-		CurrentBook       string
-		CurrentAuthor     string
-		NextBook          string
-		NextAuthor        string
-		NextBookStartDate string
-		Schedule          []struct {
-			Date     string
-			Link     string
-			CafeName string
-			BookName string
-		}
+		CurrentBook        string
+		CurrentAuthor      string
+		CurrentBookDetails string
+		CurrentBookCover   string
+		NextBook           string
+		NextAuthor         string
+		NextBookDetails    string
+		NextBookStartDate  string
+		Schedule           []renderedScheduleEntry
 	}{}
 
 	current_book, err := t.GetBookById(t.Schedule[0].BookId)
@@ -38,6 +48,8 @@ func (t *ClubTable) RenderSchedule() (string, error) {
 	}
 	rendered_schedule_data.CurrentBook = current_book.Name
 	rendered_schedule_data.CurrentAuthor = current_book.Author
+	rendered_schedule_data.CurrentBookDetails = current_book.Details()
+	rendered_schedule_data.CurrentBookCover = current_book.CoverURL
 
 	for _, schedule_entry := range t.Schedule {
 		if schedule_entry.BookId != t.Schedule[0].BookId && schedule_entry.BookId != "" {
@@ -47,6 +59,7 @@ func (t *ClubTable) RenderSchedule() (string, error) {
 			}
 			rendered_schedule_data.NextBook = next_book.Name
 			rendered_schedule_data.NextAuthor = next_book.Author
+			rendered_schedule_data.NextBookDetails = next_book.Details()
 			rendered_schedule_data.NextBookStartDate = schedule_entry.Date
 			break
 		}
@@ -57,31 +70,30 @@ func (t *ClubTable) RenderSchedule() (string, error) {
 		if i >= max_schedule_entries {
 			break
 		}
-		cafe, err := t.GetCafeById(schedule_entry.CafeId)
-		if err != nil {
-			return "", fmt.Errorf("Error getting cafe: %v", err)
+		rendered_entry := renderedScheduleEntry{
+			Date:           schedule_entry.Date,
+			InPerson:       schedule_entry.NeedsCafe(),
+			OnlineLocation: schedule_entry.OnlineLocation(),
+		}
+		rendered_entry.Going, rendered_entry.Maybe = t.Headcount(schedule_entry.Id)
+		if schedule_entry.NeedsCafe() {
+			cafe, err := t.GetCafeById(schedule_entry.CafeId)
+			if err != nil {
+				return "", fmt.Errorf("Error getting cafe: %v", err)
+			}
+			rendered_entry.CafeName = cafe.Name
+			rendered_entry.Link = cafe.Link
 		}
-		var book_name string
 		if schedule_entry.BookId == "" {
-			book_name = "TBD"
+			rendered_entry.BookName = "TBD"
 		} else {
 			book, err := t.GetBookById(schedule_entry.BookId)
 			if err != nil {
 				return "", fmt.Errorf("Error getting book: %v", err)
 			}
-			book_name = book.Name
+			rendered_entry.BookName = book.Name
 		}
-		rendered_schedule_data.Schedule = append(rendered_schedule_data.Schedule, struct {
-			Date     string
-			Link     string
-			CafeName string
-			BookName string
-		}{
-			Date:     schedule_entry.Date,
-			Link:     cafe.Link,
-			CafeName: cafe.Name,
-			BookName: book_name,
-		})
+		rendered_schedule_data.Schedule = append(rendered_schedule_data.Schedule, rendered_entry)
 	}
 
 	err = tmpl.Execute(&buf, rendered_schedule_data)
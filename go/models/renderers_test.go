@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -55,3 +56,77 @@ func TestRenderSchedule(t *testing.T) {
 	// run with -v to see
 	fmt.Print(response)
 }
+
+func TestRenderSchedule_VirtualAndHybridMeetups(t *testing.T) {
+	table := ClubTable{}
+	table.Schedule = append(table.Schedule, ScheduleEntry{
+		Id:     "123",
+		Date:   "December 20, 2025",
+		BookId: "book-1",
+		Kind:   MeetingVirtual,
+		// A stale cafe should not be shown for a virtual week.
+		CafeId:    "cafe-1",
+		VideoLink: "https://meet.example.com/club",
+	})
+	table.Schedule = append(table.Schedule, ScheduleEntry{
+		Id:             "124",
+		Date:           "December 27, 2025",
+		BookId:         "book-1",
+		CafeId:         "cafe-1",
+		Kind:           MeetingHybrid,
+		VoiceChannelId: "555",
+	})
+
+	table.BookPool = append(table.BookPool, BookEntry{
+		Id:   "book-1",
+		Name: "Example Book",
+	})
+	table.CafePool = append(table.CafePool, CafeEntry{
+		Id:   "cafe-1",
+		Name: "Example Cafe",
+		Link: "https://cafelink.com",
+	})
+
+	response, err := table.RenderSchedule()
+	if err != nil {
+		t.Fatalf("Internal error %v", err)
+	}
+	if strings.Count(response, "Example Cafe") != 1 {
+		t.Errorf("Expected only the hybrid week to list the cafe:\n%s", response)
+	}
+	if !strings.Contains(response, "[Video Call](https://meet.example.com/club)") {
+		t.Errorf("Virtual week is missing its video link:\n%s", response)
+	}
+	if !strings.Contains(response, "<#555>") {
+		t.Errorf("Hybrid week is missing its voice channel:\n%s", response)
+	}
+	// run with -v to see
+	fmt.Print(response)
+}
+
+func TestRenderSchedule_ShowsHeadcounts(t *testing.T) {
+	table := ClubTable{
+		Schedule: []ScheduleEntry{
+			{Id: "123", Date: "December 20, 2025", BookId: "book-1", Kind: MeetingVirtual},
+			{Id: "124", Date: "December 27, 2025", BookId: "book-1", Kind: MeetingVirtual},
+		},
+		BookPool: []BookEntry{{Id: "book-1", Name: "Example Book"}},
+		RSVPs: []RSVPEntry{
+			{ScheduleId: "123", UserId: "alice", Status: RSVPGoing},
+			{ScheduleId: "123", UserId: "bob", Status: RSVPGoing},
+			{ScheduleId: "123", UserId: "carol", Status: RSVPMaybe},
+			{ScheduleId: "123", UserId: "dave", Status: RSVPNo},
+		},
+	}
+
+	response, err := table.RenderSchedule()
+	if err != nil {
+		t.Fatalf("Internal error %v", err)
+	}
+	if !strings.Contains(response, "2 going, 1 maybe") {
+		t.Errorf("Expected the first meetup's headcount:\n%s", response)
+	}
+	if strings.Count(response, "Coming") != 1 {
+		t.Errorf("Expected meetups without RSVPs to leave out the headcount:\n%s", response)
+	}
+}
@@ -1,16 +1,43 @@
 package models
 
+import "time"
+
 type CafeEntry struct {
 	Id   string `json:"id"`
 	Name string `json:"name"`
 	Link string `json:"link"`
+	// How many members the cafe can seat. Zero means unknown.
+	Capacity int `json:"capacity,omitempty"`
 }
 
+// MeetingKind describes where a meetup happens. The zero value is treated as
+// an in-person meetup so older club tables keep their behavior.
+type MeetingKind string
+
+const (
+	MeetingInPerson MeetingKind = "in_person"
+	MeetingVirtual  MeetingKind = "virtual"
+	MeetingHybrid   MeetingKind = "hybrid"
+)
+
 type ScheduleEntry struct {
 	Id     string `json:"id"`
 	Date   string `json:"date"`
 	BookId string `json:"book_id"`
 	CafeId string `json:"cafe_id"`
+
+	Kind MeetingKind `json:"kind,omitempty"`
+	// Where to join online for virtual and hybrid meetups. Either or both may be set.
+	VideoLink      string `json:"video_link,omitempty"`
+	VoiceChannelId string `json:"voice_channel_id,omitempty"`
+}
+
+// BookMetadata is book information looked up from a catalog rather than typed by members.
+type BookMetadata struct {
+	CoverURL    string   `json:"cover_url,omitempty"`
+	PageCount   int      `json:"page_count,omitempty"`
+	PublishYear int      `json:"publish_year,omitempty"`
+	Subjects    []string `json:"subjects,omitempty"`
 }
 
 type BookEntry struct {
@@ -21,10 +48,201 @@ type BookEntry struct {
 	Description string `json:"description"`
 	Votes       int    `json:"votes"`
 	Read        bool   `json:"read"`
+	// When the book was picked to be read. Zero for books read before this was recorded.
+	ReadAt time.Time `json:"read_at,omitzero"`
+
+	ISBN          string `json:"isbn,omitempty"`
+	Genre         string `json:"genre,omitempty"`
+	RecommenderId string `json:"recommender_id,omitempty"`
+	BookMetadata
+
+	// Archived books went too long without activity and can't be picked until revived.
+	Archived  bool      `json:"archived,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitzero"`
+	RevivedAt time.Time `json:"revived_at,omitzero"`
+	// The book's votes weighted by age, set only while picking a book with vote aging on.
+	AgedVotes *float64 `json:"-"`
+
+	ContentWarnings []string `json:"content_warnings,omitempty"`
+	// Vetoed books passed the club's veto threshold and can't be picked.
+	// Derived from ClubTable.Vetoes.
+	Vetoed bool `json:"vetoed,omitempty"`
+
+	// Queued books won a poll and are scheduled ahead of any other pick.
+	Queued bool `json:"queued,omitempty"`
+
+	// The recommendation embed members vote on.
+	MessageId string `json:"message_id,omitempty"`
+	ChannelId string `json:"channel_id,omitempty"`
+}
+
+// VoteEntry records a single member's vote for a book. BookEntry.Votes is derived from these.
+type VoteEntry struct {
+	UserId    string    `json:"user_id"`
+	BookId    string    `json:"book_id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// VetoEntry records an anonymous veto. Instead of the voter, it keeps a salted hash of the
+// voter and season, which is enough to enforce the per-season limit.
+type VetoEntry struct {
+	Voter     string    `json:"voter"`
+	BookId    string    `json:"book_id"`
+	Season    string    `json:"season"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// BallotEntry is one member's ranked ballot. Rankings holds book IDs, first choice first.
+type BallotEntry struct {
+	UserId    string    `json:"user_id"`
+	Rankings  []string  `json:"rankings"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// PollEntry is a ranked-choice poll among finalist books.
+type PollEntry struct {
+	Id        string        `json:"id"`
+	ChannelId string        `json:"channel_id"`
+	MessageId string        `json:"message_id"`
+	Finalists []string      `json:"finalists"`
+	Deadline  time.Time     `json:"deadline"`
+	Ballots   []BallotEntry `json:"ballots"`
+	Closed    bool          `json:"closed"`
+	WinnerId  string        `json:"winner_id,omitempty"`
+}
+
+// Capability is something a member is allowed to do in the club.
+type Capability string
+
+const (
+	CapabilityRecommend    Capability = "recommend"
+	CapabilityVote         Capability = "vote"
+	CapabilityPlanSchedule Capability = "plan_schedule"
+	CapabilityManageCafes  Capability = "manage_cafes"
+	// Admins can do everything, including changing who can do what.
+	CapabilityAdmin Capability = "admin"
+)
+
+// RoleGrantEntry gives everyone with a Discord role some capabilities. A grant for the
+// guild's @everyone role, whose ID is the guild ID, replaces the default capabilities.
+type RoleGrantEntry struct {
+	GuildId      string       `json:"guild_id"`
+	RoleId       string       `json:"role_id"`
+	Capabilities []Capability `json:"capabilities"`
+}
+
+// ReminderSubscriptionEntry is a member who asked for meetup reminders by DM.
+type ReminderSubscriptionEntry struct {
+	UserId string `json:"user_id"`
+	// The member's own reminder offsets. Empty uses the club's.
+	OffsetMinutes []int `json:"offset_minutes,omitempty"`
+}
+
+// SentReminderEntry records a reminder so it is only sent once. Recipient is a
+// user ID, or "channel" for the reminder channel.
+type SentReminderEntry struct {
+	ScheduleId    string `json:"schedule_id"`
+	Recipient     string `json:"recipient"`
+	OffsetMinutes int    `json:"offset_minutes"`
+}
+
+// RSVPStatus is a member's answer to whether they are coming to a meetup.
+type RSVPStatus string
+
+const (
+	RSVPGoing RSVPStatus = "going"
+	RSVPMaybe RSVPStatus = "maybe"
+	RSVPNo    RSVPStatus = "no"
+)
+
+type RSVPEntry struct {
+	ScheduleId string     `json:"schedule_id"`
+	UserId     string     `json:"user_id"`
+	Status     RSVPStatus `json:"status"`
+}
+
+// AttendanceEntry records who came to a meetup. The meetup's details are copied so
+// stats survive it leaving the schedule.
+type AttendanceEntry struct {
+	ScheduleId string   `json:"schedule_id"`
+	Date       string   `json:"date"`
+	BookId     string   `json:"book_id,omitempty"`
+	CafeId     string   `json:"cafe_id,omitempty"`
+	Attendees  []string `json:"attendees"`
+}
+
+// ScheduledEventEntry links a meetup to the Discord scheduled event mirroring it.
+type ScheduledEventEntry struct {
+	ScheduleId string `json:"schedule_id"`
+	EventId    string `json:"event_id"`
+	// Describes the event as last sent to Discord, so unchanged events are left alone.
+	Fingerprint string `json:"fingerprint"`
+	// Voice events are hosted in a voice channel rather than at an external location.
+	Voice bool `json:"voice,omitempty"`
+}
+
+// JobRunEntry records when a scheduled job last ran, so missed runs can be caught up on.
+type JobRunEntry struct {
+	Name    string    `json:"name"`
+	LastRun time.Time `json:"last_run"`
+}
+
+// ClubSettings holds the per-club knobs organizers can change.
+type ClubSettings struct {
+	// The most books a member can vote for at once. Zero means no limit.
+	MaxVotesPerMember int `json:"max_votes_per_member,omitempty"`
+	// Name of the strategy used to pick the next book. Empty uses the default.
+	SelectionStrategy string `json:"selection_strategy,omitempty"`
+	// A vote loses half its weight every VoteHalfLifeDays. Zero disables decay.
+	VoteHalfLifeDays int `json:"vote_half_life_days,omitempty"`
+	// Only votes cast within the last VoteWindowMonths count. Zero counts every vote.
+	VoteWindowMonths int `json:"vote_window_months,omitempty"`
+	// Unread books with no activity for ArchiveAfterMonths are archived. Zero never archives.
+	ArchiveAfterMonths int `json:"archive_after_months,omitempty"`
+	// Stops the same member's recommendations from being picked twice in a row.
+	NoBackToBackRecommender bool `json:"no_back_to_back_recommender,omitempty"`
+	// How many vetoes each member gets per season. Zero turns vetoes off.
+	VetoesPerSeason int `json:"vetoes_per_season,omitempty"`
+	// A book with at least VetoThreshold vetoes can't be picked.
+	VetoThreshold int `json:"veto_threshold,omitempty"`
+	// Where weekly announcements are posted. Empty posts where the command was run.
+	AnnouncementChannelId string `json:"announcement_channel_id,omitempty"`
+	// When the weekly announcement is posted, as a cron spec such as "0 18 * * 4".
+	// Empty turns automatic announcements off.
+	AnnouncementSchedule string `json:"announcement_schedule,omitempty"`
+	// The IANA time zone scheduled jobs run in, e.g. "America/Chicago". Empty uses the server's.
+	TimeZone string `json:"time_zone,omitempty"`
+	// The local time meetups start, e.g. "14:00". Empty means 2pm.
+	MeetupTime string `json:"meetup_time,omitempty"`
+	// Where meetup reminders are posted. Empty turns channel reminders off.
+	ReminderChannelId string `json:"reminder_channel_id,omitempty"`
+	// The role mentioned by channel reminders, if any.
+	ReminderRoleId string `json:"reminder_role_id,omitempty"`
+	// How many minutes before a meetup reminders go out. Empty uses the defaults.
+	ReminderOffsetMinutes []int `json:"reminder_offset_minutes,omitempty"`
+	// The guild upcoming meetups are mirrored to as scheduled events. Empty turns events off.
+	EventsGuildId string `json:"events_guild_id,omitempty"`
 }
 
 type ClubTable struct {
 	CafePool []CafeEntry     `json:"cafe_pool"`
 	Schedule []ScheduleEntry `json:"schedule"`
 	BookPool []BookEntry     `json:"book_pool"`
+	Votes    []VoteEntry     `json:"votes"`
+	Polls    []PollEntry     `json:"polls"`
+	Vetoes   []VetoEntry     `json:"vetoes"`
+	Settings ClubSettings    `json:"settings"`
+	// Salts the voter hashes in Vetoes. Generated on the first veto.
+	VetoSalt string `json:"veto_salt,omitempty"`
+	// Role grants for every guild the bot serves.
+	RoleGrants []RoleGrantEntry `json:"role_grants,omitempty"`
+	JobRuns    []JobRunEntry    `json:"job_runs,omitempty"`
+
+	ReminderSubscriptions []ReminderSubscriptionEntry `json:"reminder_subscriptions,omitempty"`
+	SentReminders         []SentReminderEntry         `json:"sent_reminders,omitempty"`
+
+	RSVPs      []RSVPEntry       `json:"rsvps,omitempty"`
+	Attendance []AttendanceEntry `json:"attendance,omitempty"`
+
+	ScheduledEvents []ScheduledEventEntry `json:"scheduled_events,omitempty"`
 }
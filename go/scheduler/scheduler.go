@@ -0,0 +1,115 @@
+// Package scheduler runs recurring jobs in-process on cron-like schedules. The last run of each
+// job is persisted, so runs missed while the bot was down can be caught up on when it restarts.
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Clock tells the scheduler the time. Tests use a fake clock to control it.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// RealClock is the system clock.
+var RealClock Clock = realClock{}
+
+// StateStore persists when each job last ran. A zero time means it never has.
+type StateStore interface {
+	LastRun(name string) time.Time
+	SetLastRun(name string, at time.Time) error
+}
+
+type Job struct {
+	// Identifies the job's state, so it must not change between releases.
+	Name string
+	// Schedule is checked before every run, so jobs follow configuration changes.
+	// Returning false turns the job off.
+	Schedule func() (Spec, bool)
+	// How late a run missed during downtime can still happen. Older runs are skipped.
+	// Runs up to one Interval late always happen.
+	CatchUp time.Duration
+	// Run is passed the time the run was due.
+	Run func(due time.Time) error
+}
+
+type Scheduler struct {
+	clock Clock
+	store StateStore
+	jobs  []Job
+	// How often the scheduler checks for due jobs. Specs are accurate to the minute.
+	Interval time.Duration
+}
+
+func New(clock Clock, store StateStore) *Scheduler {
+	return &Scheduler{clock: clock, store: store, Interval: time.Minute}
+}
+
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// Run checks for due jobs until the context is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		s.Tick()
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.clock.After(s.Interval):
+		}
+	}
+}
+
+// Tick runs every job that has come due since it last ran. Several missed runs
+// of a job are collapsed into the most recent one. A run that fails isn't recorded,
+// so it is tried again on the next tick while it is within its catch-up window.
+func (s *Scheduler) Tick() {
+	now := s.clock.Now()
+	for _, job := range s.jobs {
+		spec, ok := job.Schedule()
+		if !ok {
+			continue
+		}
+		last := s.store.LastRun(job.Name)
+		if last.IsZero() {
+			// A new job waits for its first scheduled time instead of running straight away.
+			s.recordRun(job, now)
+			continue
+		}
+		due := spec.Next(last)
+		if due.IsZero() || due.After(now) {
+			continue
+		}
+		for next := spec.Next(due); !next.IsZero() && !next.After(now); next = spec.Next(next) {
+			due = next
+		}
+
+		if now.Sub(due) > max(job.CatchUp, s.Interval) {
+			log.Printf("Skipping job %s, which was due at %v", job.Name, due)
+		} else {
+			log.Printf("Running job %s, which was due at %v", job.Name, due)
+			err := job.Run(due)
+			if err != nil {
+				log.Printf("Error running job %s: %v", job.Name, err)
+				continue
+			}
+		}
+		s.recordRun(job, now)
+	}
+}
+
+func (s *Scheduler) recordRun(job Job, at time.Time) {
+	err := s.store.SetLastRun(job.Name, at)
+	if err != nil {
+		log.Printf("Unable to record run of job %s: %v", job.Name, err)
+	}
+}
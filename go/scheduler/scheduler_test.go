@@ -0,0 +1,235 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock only moves when a test advances it.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+	waiting chan struct{}
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, waiting: make(chan struct{}, 100)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	c.waiting <- struct{}{}
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := []fakeWaiter{}
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			pending = append(pending, w)
+		} else {
+			w.ch <- c.now
+		}
+	}
+	c.waiters = pending
+}
+
+type memoryStore struct {
+	mu   sync.Mutex
+	runs map[string]time.Time
+}
+
+func (m *memoryStore) LastRun(name string) time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.runs[name]
+}
+
+func (m *memoryStore) SetLastRun(name string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs[name] = at
+	return nil
+}
+
+// Wednesday, October 14, 2026.
+var start = time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC)
+
+func weeklyJob(t *testing.T, runs *[]time.Time) Job {
+	spec, err := ParseSpec("0 18 * * 4", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Job{
+		Name:     "weekly",
+		Schedule: func() (Spec, bool) { return spec, true },
+		CatchUp:  12 * time.Hour,
+		Run: func(due time.Time) error {
+			*runs = append(*runs, due)
+			return nil
+		},
+	}
+}
+
+func TestScheduler_RunsWhenDue(t *testing.T) {
+	clock := newFakeClock(start)
+	store := &memoryStore{runs: map[string]time.Time{}}
+	runs := []time.Time{}
+	s := New(clock, store)
+	s.Add(weeklyJob(t, &runs))
+
+	s.Tick()
+	if len(runs) != 0 || !store.LastRun("weekly").Equal(start) {
+		t.Fatalf("Expected a new job to wait for its first run, ran %v", runs)
+	}
+
+	clock.Advance(32*time.Hour + 29*time.Minute)
+	s.Tick()
+	if len(runs) != 0 {
+		t.Fatalf("Expected no run before 6pm Thursday, ran %v", runs)
+	}
+	clock.Advance(time.Minute)
+	s.Tick()
+	s.Tick()
+	if len(runs) != 1 || !runs[0].Equal(time.Date(2026, 10, 15, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected one run at 6pm Thursday, ran %v", runs)
+	}
+}
+
+func TestScheduler_RetriesFailedRuns(t *testing.T) {
+	clock := newFakeClock(time.Date(2026, 10, 15, 18, 0, 0, 0, time.UTC))
+	store := &memoryStore{runs: map[string]time.Time{"weekly": start}}
+	runs := []time.Time{}
+	job := weeklyJob(t, &runs)
+	succeed := job.Run
+	failures := 1
+	job.Run = func(due time.Time) error {
+		if failures > 0 {
+			failures--
+			return errors.New("Discord is down")
+		}
+		return succeed(due)
+	}
+	s := New(clock, store)
+	s.Add(job)
+
+	s.Tick()
+	if !store.LastRun("weekly").Equal(start) {
+		t.Fatalf("Expected the failed run not to be recorded")
+	}
+	clock.Advance(time.Minute)
+	s.Tick()
+	if len(runs) != 1 || !runs[0].Equal(time.Date(2026, 10, 15, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the failed run to be retried, ran %v", runs)
+	}
+	if !store.LastRun("weekly").Equal(clock.Now()) {
+		t.Errorf("Expected the retried run to be recorded")
+	}
+}
+
+func TestScheduler_CatchesUpAfterDowntime(t *testing.T) {
+	// The bot went down before two runs and came back an hour after the second.
+	clock := newFakeClock(time.Date(2026, 10, 22, 19, 0, 0, 0, time.UTC))
+	store := &memoryStore{runs: map[string]time.Time{"weekly": time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)}}
+	runs := []time.Time{}
+	s := New(clock, store)
+	s.Add(weeklyJob(t, &runs))
+
+	s.Tick()
+	if len(runs) != 1 || !runs[0].Equal(time.Date(2026, 10, 22, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected a single catch-up run for the latest missed time, ran %v", runs)
+	}
+	if !store.LastRun("weekly").Equal(clock.Now()) {
+		t.Errorf("Expected the catch-up run to be recorded")
+	}
+}
+
+func TestScheduler_SkipsStaleRuns(t *testing.T) {
+	clock := newFakeClock(time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC))
+	store := &memoryStore{runs: map[string]time.Time{"weekly": time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)}}
+	runs := []time.Time{}
+	s := New(clock, store)
+	s.Add(weeklyJob(t, &runs))
+
+	s.Tick()
+	if len(runs) != 0 {
+		t.Errorf("Expected a run missed by days to be skipped, ran %v", runs)
+	}
+	if !store.LastRun("weekly").Equal(clock.Now()) {
+		t.Errorf("Expected the skipped run to be recorded so it isn't retried")
+	}
+}
+
+func TestScheduler_DisabledJobsDoNotRun(t *testing.T) {
+	clock := newFakeClock(time.Date(2026, 10, 15, 18, 0, 0, 0, time.UTC))
+	store := &memoryStore{runs: map[string]time.Time{"weekly": start}}
+	runs := []time.Time{}
+	job := weeklyJob(t, &runs)
+	job.Schedule = func() (Spec, bool) { return Spec{}, false }
+	s := New(clock, store)
+	s.Add(job)
+
+	s.Tick()
+	if len(runs) != 0 || !store.LastRun("weekly").Equal(start) {
+		t.Errorf("Expected a disabled job to be left alone, ran %v", runs)
+	}
+}
+
+func TestScheduler_RunLoopFollowsClock(t *testing.T) {
+	clock := newFakeClock(time.Date(2026, 10, 15, 17, 58, 0, 0, time.UTC))
+	store := &memoryStore{runs: map[string]time.Time{"weekly": start}}
+	ran := make(chan time.Time, 1)
+	job := weeklyJob(t, nil)
+	job.Run = func(due time.Time) error {
+		ran <- due
+		return nil
+	}
+	s := New(clock, store)
+	s.Add(job)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	for minute := 0; minute < 2; minute++ {
+		<-clock.waiting
+		clock.Advance(time.Minute)
+	}
+	select {
+	case due := <-ran:
+		if !due.Equal(time.Date(2026, 10, 15, 18, 0, 0, 0, time.UTC)) {
+			t.Errorf("Unexpected due time %v", due)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the job to run at 6pm")
+	}
+
+	cancel()
+	<-clock.waiting
+	clock.Advance(time.Minute)
+	<-done
+}
@@ -0,0 +1,142 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Spec is a cron-like schedule: "minute hour day-of-month month day-of-week".
+// Each field is "*", a number, a range "a-b", a list "a,b" or a step "*/n" or "a-b/n".
+// Days of the week run from 0 (Sunday) to 6, and 7 is also Sunday.
+type Spec struct {
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+	// Cron matches either day field when both are restricted.
+	anyDay     bool
+	anyWeekday bool
+	location   *time.Location
+}
+
+type specField struct {
+	name     string
+	min, max int
+}
+
+var specFields = []specField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// ParseSpec reads a cron-like spec whose times are in the given location.
+func ParseSpec(spec string, location *time.Location) (Spec, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != len(specFields) {
+		return Spec{}, fmt.Errorf("Expected %d fields in schedule '%s', found %d", len(specFields), spec, len(fields))
+	}
+	sets := make([]uint64, len(fields))
+	for n, field := range fields {
+		set, err := parseSpecField(field, specFields[n])
+		if err != nil {
+			return Spec{}, err
+		}
+		sets[n] = set
+	}
+	// Fold 7 into Sunday.
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+	if location == nil {
+		location = time.Local
+	}
+	return Spec{
+		minutes:    sets[0],
+		hours:      sets[1],
+		days:       sets[2],
+		months:     sets[3],
+		weekdays:   sets[4],
+		anyDay:     fields[2] == "*",
+		anyWeekday: fields[4] == "*",
+		location:   location,
+	}, nil
+}
+
+func parseSpecField(field string, f specField) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		values, step, hasStep := strings.Cut(part, "/")
+		every := 1
+		if hasStep {
+			var err error
+			every, err = strconv.Atoi(step)
+			if err != nil || every < 1 {
+				return 0, fmt.Errorf("Invalid step '%s' in %s", step, f.name)
+			}
+		}
+		low, high := f.min, f.max
+		if values != "*" {
+			first, last, isRange := strings.Cut(values, "-")
+			var err error
+			low, err = strconv.Atoi(first)
+			if err != nil {
+				return 0, fmt.Errorf("Invalid %s '%s'", f.name, first)
+			}
+			high = low
+			if isRange {
+				high, err = strconv.Atoi(last)
+				if err != nil {
+					return 0, fmt.Errorf("Invalid %s '%s'", f.name, last)
+				}
+			} else if hasStep {
+				high = f.max
+			}
+		}
+		if low < f.min || high > f.max || low > high {
+			return 0, fmt.Errorf("The %s '%s' is out of range %d-%d", f.name, values, f.min, f.max)
+		}
+		for v := low; v <= high; v += every {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func (s Spec) dayMatches(t time.Time) bool {
+	day := s.days&(1<<t.Day()) != 0
+	weekday := s.weekdays&(1<<int(t.Weekday())) != 0
+	if s.anyDay || s.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+// Next returns the first time the spec matches strictly after the given time,
+// or the zero time if it never does.
+func (s Spec) Next(after time.Time) time.Time {
+	t := after.In(s.location)
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, s.location)
+	// Any valid spec matches within a few years, allowing for February 29th.
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.months&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+		case s.hours&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location)
+		case s.minutes&(1<<t.Minute()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, s.location)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpec_Next(t *testing.T) {
+	// Wednesday, October 14, 2026.
+	wednesday := time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC)
+	cases := []struct {
+		spec     string
+		after    time.Time
+		expected time.Time
+	}{
+		{"0 18 * * 4", wednesday, time.Date(2026, 10, 15, 18, 0, 0, 0, time.UTC)},
+		{"0 18 * * 4", time.Date(2026, 10, 15, 18, 0, 0, 0, time.UTC), time.Date(2026, 10, 22, 18, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", wednesday, time.Date(2026, 10, 14, 9, 45, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", wednesday, time.Date(2026, 10, 14, 13, 0, 0, 0, time.UTC)},
+		{"30 8 1 * *", wednesday, time.Date(2026, 11, 1, 8, 30, 0, 0, time.UTC)},
+		{"0 0 29 2 *", wednesday, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 0,6", wednesday, time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", wednesday, time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)},
+		// Either day field matches when both are restricted.
+		{"0 0 20 * 5", wednesday, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		spec, err := ParseSpec(c.spec, time.UTC)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing '%s': %v", c.spec, err)
+		}
+		if next := spec.Next(c.after); !next.Equal(c.expected) {
+			t.Errorf("'%s' after %v: expected %v, got %v", c.spec, c.after, c.expected, next)
+		}
+	}
+}
+
+func TestSpec_NextInLocation(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skip("Time zone data is unavailable")
+	}
+	spec, err := ParseSpec("0 18 * * *", chicago)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := spec.Next(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC))
+	if !next.Equal(time.Date(2026, 10, 14, 23, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected 6pm in Chicago, got %v", next.UTC())
+	}
+}
+
+func TestParseSpec_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := ParseSpec(spec, time.UTC)
+		if err == nil {
+			t.Errorf("Expected '%s' to be rejected", spec)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"bookclubbot.com/main/models"
+)
+
+const (
+	DefaultOpenLibraryURL = "https://openlibrary.org"
+	DefaultCoversURL      = "https://covers.openlibrary.org"
+	// Only the first few subjects are kept; Open Library lists dozens for popular books.
+	maxSubjects = 5
+)
+
+var ErrBookNotFound = errors.New("Book not found")
+
+// BookEnricher looks up catalog metadata for a book. The ISBN is preferred when known.
+type BookEnricher interface {
+	Enrich(ctx context.Context, title string, author string, isbn string) (models.BookMetadata, string, error)
+}
+
+// OpenLibraryClient enriches books from an Open Library compatible search API. Results,
+// including misses, are cached for CacheTTL.
+type OpenLibraryClient struct {
+	BaseURL    string
+	CoversURL  string
+	HTTPClient *http.Client
+	CacheTTL   time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedLookup
+}
+
+type cachedLookup struct {
+	metadata models.BookMetadata
+	isbn     string
+	err      error
+	expires  time.Time
+}
+
+func NewOpenLibraryClient(baseURL string, coversURL string) *OpenLibraryClient {
+	if baseURL == "" {
+		baseURL = DefaultOpenLibraryURL
+	}
+	if coversURL == "" {
+		coversURL = DefaultCoversURL
+	}
+	return &OpenLibraryClient{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		CoversURL:  strings.TrimSuffix(coversURL, "/"),
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		CacheTTL:   24 * time.Hour,
+		cache:      map[string]cachedLookup{},
+	}
+}
+
+type openLibrarySearchResponse struct {
+	Docs []struct {
+		Title               string   `json:"title"`
+		FirstPublishYear    int      `json:"first_publish_year"`
+		NumberOfPagesMedian int      `json:"number_of_pages_median"`
+		CoverId             int      `json:"cover_i"`
+		ISBN                []string `json:"isbn"`
+		Subject             []string `json:"subject"`
+	} `json:"docs"`
+}
+
+// Enrich returns the metadata and ISBN of the best match for a book.
+func (c *OpenLibraryClient) Enrich(ctx context.Context, title string, author string, isbn string) (models.BookMetadata, string, error) {
+	query := url.Values{}
+	query.Set("limit", "1")
+	query.Set("fields", "title,first_publish_year,number_of_pages_median,cover_i,isbn,subject")
+	if isbn != "" {
+		query.Set("isbn", isbn)
+	} else {
+		query.Set("title", title)
+		if author != "" {
+			query.Set("author", author)
+		}
+	}
+	cache_key := strings.ToLower(query.Encode())
+
+	c.mu.Lock()
+	cached, ok := c.cache[cache_key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.metadata, cached.isbn, cached.err
+	}
+
+	metadata, found_isbn, err := c.search(ctx, query)
+	// Transient failures aren't cached so the next lookup can retry.
+	if err == nil || errors.Is(err, ErrBookNotFound) {
+		c.mu.Lock()
+		c.cache[cache_key] = cachedLookup{
+			metadata: metadata,
+			isbn:     found_isbn,
+			err:      err,
+			expires:  time.Now().Add(c.CacheTTL),
+		}
+		c.mu.Unlock()
+	}
+	return metadata, found_isbn, err
+}
+
+func (c *OpenLibraryClient) search(ctx context.Context, query url.Values) (models.BookMetadata, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/search.json?"+query.Encode(), nil)
+	if err != nil {
+		return models.BookMetadata{}, "", fmt.Errorf("Unable to build Open Library request: %w", err)
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return models.BookMetadata{}, "", fmt.Errorf("Unable to reach Open Library: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return models.BookMetadata{}, "", fmt.Errorf("Open Library returned status %d", resp.StatusCode)
+	}
+
+	var body openLibrarySearchResponse
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return models.BookMetadata{}, "", fmt.Errorf("Unable to decode Open Library response: %w", err)
+	}
+	if len(body.Docs) == 0 {
+		return models.BookMetadata{}, "", ErrBookNotFound
+	}
+
+	doc := body.Docs[0]
+	metadata := models.BookMetadata{
+		PageCount:   doc.NumberOfPagesMedian,
+		PublishYear: doc.FirstPublishYear,
+		Subjects:    doc.Subject[:min(maxSubjects, len(doc.Subject))],
+	}
+	if doc.CoverId != 0 {
+		metadata.CoverURL = fmt.Sprintf("%s/b/id/%d-M.jpg", c.CoversURL, doc.CoverId)
+	}
+	found_isbn := ""
+	if len(doc.ISBN) > 0 {
+		found_isbn = doc.ISBN[0]
+	}
+	return metadata, found_isbn, nil
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// newFakeOpenLibrary serves search results for "The Hobbit" and nothing else.
+func newFakeOpenLibrary(t *testing.T, requests *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/search.json" {
+			http.NotFound(w, r)
+			return
+		}
+		query := r.URL.Query()
+		if query.Get("title") == "The Hobbit" || query.Get("isbn") == "9780261102217" {
+			fmt.Fprint(w, `{"docs": [{
+				"title": "The Hobbit",
+				"first_publish_year": 1937,
+				"number_of_pages_median": 310,
+				"cover_i": 14627509,
+				"isbn": ["9780261102217"],
+				"subject": ["Fantasy", "Dragons", "Dwarves", "Wizards", "Elves", "Hobbits"]
+			}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"docs": []}`)
+	}))
+}
+
+func TestOpenLibraryClient_Enrich(t *testing.T) {
+	var requests atomic.Int32
+	server := newFakeOpenLibrary(t, &requests)
+	defer server.Close()
+
+	client := NewOpenLibraryClient(server.URL, "https://covers.example.com")
+	metadata, isbn, err := client.Enrich(context.Background(), "The Hobbit", "J.R.R. Tolkien", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if metadata.PageCount != 310 || metadata.PublishYear != 1937 {
+		t.Errorf("Unexpected metadata %+v", metadata)
+	}
+	if metadata.CoverURL != "https://covers.example.com/b/id/14627509-M.jpg" {
+		t.Errorf("Unexpected cover URL %s", metadata.CoverURL)
+	}
+	if len(metadata.Subjects) != maxSubjects {
+		t.Errorf("Expected %d subjects, got %v", maxSubjects, metadata.Subjects)
+	}
+	if isbn != "9780261102217" {
+		t.Errorf("Unexpected ISBN %s", isbn)
+	}
+
+	// A repeated lookup is served from the cache.
+	_, _, err = client.Enrich(context.Background(), "The Hobbit", "J.R.R. Tolkien", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected 1 request to the server, got %d", requests.Load())
+	}
+
+	// Known ISBNs are looked up directly.
+	_, _, err = client.Enrich(context.Background(), "Wrong Title", "", "9780261102217")
+	if err != nil {
+		t.Errorf("Expected the ISBN lookup to succeed: %v", err)
+	}
+}
+
+func TestOpenLibraryClient_NotFound(t *testing.T) {
+	var requests atomic.Int32
+	server := newFakeOpenLibrary(t, &requests)
+	defer server.Close()
+
+	client := NewOpenLibraryClient(server.URL, "")
+	for range 2 {
+		_, _, err := client.Enrich(context.Background(), "Not A Real Book", "", "")
+		if !errors.Is(err, ErrBookNotFound) {
+			t.Errorf("Expected ErrBookNotFound, got %v", err)
+		}
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Misses should be cached, got %d requests", requests.Load())
+	}
+}
+
+func TestOpenLibraryClient_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewOpenLibraryClient(server.URL, "")
+	_, _, err := client.Enrich(context.Background(), "The Hobbit", "", "")
+	if err == nil || errors.Is(err, ErrBookNotFound) {
+		t.Errorf("Expected a server error, got %v", err)
+	}
+}
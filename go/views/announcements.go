@@ -0,0 +1,186 @@
+package views
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/controllers"
+	"bookclubbot.com/main/models"
+)
+
+const (
+	announcementPostPrefix   = "announcement_post_"
+	announcementCancelPrefix = "announcement_cancel"
+	rsvpButtonPrefix         = "rsvp_"
+)
+
+// The channel types an announcement can be posted to.
+var announcementChannelTypes = []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildNews}
+
+// HandleMakeAnnouncement shows organizers a private preview of this week's announcement
+// with buttons to post or cancel it.
+func HandleMakeAnnouncement(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	err := planSchedule(t)
+	if err != nil {
+		return respondEphemeral(s, i, fmt.Sprintf("Unable to plan the schedule: %v", err))
+	}
+	announcement, err := controllers.ComposeAnnouncement(t, time.Now())
+	if err != nil {
+		return respondEphemeral(s, i, fmt.Sprintf("Unable to make an announcement: %v", err))
+	}
+
+	channelId := announcementChannel(t, i)
+	if option := i.ApplicationCommandData().GetOption("channel"); option != nil {
+		channelId = option.ChannelValue(nil).ID
+	}
+
+	err = respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Here's a preview. Post it in <#%s>?", channelId),
+			Embeds:  []*discordgo.MessageEmbed{announcementEmbed(announcement)},
+			Flags:   discordgo.MessageFlagsEphemeral,
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.Button{
+							Label:    "Post",
+							Style:    discordgo.SuccessButton,
+							Emoji:    &discordgo.ComponentEmoji{Name: "📣"},
+							CustomID: announcementPostPrefix + channelId + "_" + announcement.Meetup.Id,
+						},
+						discordgo.Button{
+							Label:    "Cancel",
+							Style:    discordgo.SecondaryButton,
+							CustomID: announcementCancelPrefix,
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to send announcement preview: %v", err)
+	}
+	return nil
+}
+
+// announcementChannel is the configured announcement channel, falling back to where the interaction happened.
+func announcementChannel(t *models.ClubTable, i *discordgo.InteractionCreate) string {
+	if t.Settings.AnnouncementChannelId != "" {
+		return t.Settings.AnnouncementChannelId
+	}
+	return i.ChannelID
+}
+
+// HandleAnnouncementPost posts the previewed announcement as it was shown.
+func HandleAnnouncementPost(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	target := strings.TrimPrefix(i.MessageComponentData().CustomID, announcementPostPrefix)
+	channelId, scheduleId, found := strings.Cut(target, "_")
+	if !found {
+		return fmt.Errorf("Malformed announcement button: %s", target)
+	}
+
+	err := postAnnouncement(s, channelId, scheduleId, i.Message.Embeds)
+	if err != nil {
+		log.Println(err)
+		return updateEphemeralPrompt(s, i, fmt.Sprintf("Unable to post the announcement in <#%s>. Check that I can send messages there.", channelId))
+	}
+	return updateEphemeralPrompt(s, i, fmt.Sprintf("📣 Posted in <#%s>.", channelId))
+}
+
+func HandleAnnouncementCancel(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	return updateEphemeralPrompt(s, i, "The announcement was not posted.")
+}
+
+func postAnnouncement(s *discordgo.Session, channelId string, scheduleId string, embeds []*discordgo.MessageEmbed) error {
+	_, err := s.ChannelMessageSendComplex(channelId, &discordgo.MessageSend{
+		Embeds:     embeds,
+		Components: rsvpButtons(scheduleId),
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to post announcement: %v", err)
+	}
+	return nil
+}
+
+func announcementEmbed(a controllers.Announcement) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       "📣 This Week at Book Club",
+		Description: fmt.Sprintf("Our next meetup is on **%s**. Let us know if you're coming!", a.Meetup.Date),
+		Color:       0x5865F2,
+		Fields:      []*discordgo.MessageEmbedField{},
+	}
+	if a.Book.Id == "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "📖 Reading", Value: "TBD"})
+	} else {
+		embed.Fields = append(embed.Fields,
+			&discordgo.MessageEmbedField{Name: "📖 Reading", Value: fmt.Sprintf("*%s* by %s", a.Book.Name, a.Book.Author)},
+			&discordgo.MessageEmbedField{Name: "🎯 Reading Target", Value: describeReadingTarget(a)},
+		)
+		if a.Book.CoverURL != "" {
+			embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: a.Book.CoverURL}
+		}
+	}
+	if a.Cafe.Id != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "📍 Meeting Location",
+			Value: fmt.Sprintf("%s ([Directions](%s))", a.Cafe.Name, a.Cafe.Link),
+		})
+	}
+	if a.Meetup.HasOnlineLocation() {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "💻 Join Online", Value: a.Meetup.OnlineLocation()})
+	}
+	return embed
+}
+
+func describeReadingTarget(a controllers.Announcement) string {
+	switch {
+	case a.Part == a.Parts && a.TargetPage > 0:
+		return fmt.Sprintf("Finish the book (page %d)", a.TargetPage)
+	case a.Part == a.Parts:
+		return "Finish the book"
+	case a.TargetPage > 0:
+		return fmt.Sprintf("Read through page %d (part %d of %d)", a.TargetPage, a.Part, a.Parts)
+	default:
+		return fmt.Sprintf("Part %d of %d", a.Part, a.Parts)
+	}
+}
+
+// RSVP buttons carry the status and the meetup they are for, e.g. "rsvp_going_<schedule ID>".
+func rsvpButtons(scheduleId string) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Going",
+					Style:    discordgo.SuccessButton,
+					Emoji:    &discordgo.ComponentEmoji{Name: "✅"},
+					CustomID: rsvpButtonPrefix + "going_" + scheduleId,
+				},
+				discordgo.Button{
+					Label:    "Maybe",
+					Style:    discordgo.SecondaryButton,
+					Emoji:    &discordgo.ComponentEmoji{Name: "🤔"},
+					CustomID: rsvpButtonPrefix + "maybe_" + scheduleId,
+				},
+				discordgo.Button{
+					Label:    "Can't make it",
+					Style:    discordgo.SecondaryButton,
+					Emoji:    &discordgo.ComponentEmoji{Name: "❌"},
+					CustomID: rsvpButtonPrefix + "no_" + scheduleId,
+				},
+			},
+		},
+	}
+}
+
+func HandleSetAnnouncementChannel(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	channel := i.ApplicationCommandData().GetOption("channel").ChannelValue(nil)
+	t.Settings.AnnouncementChannelId = channel.ID
+	return respondEphemeral(s, i, fmt.Sprintf("Announcements will be posted in <#%s>.", channel.ID))
+}
@@ -0,0 +1,53 @@
+package views
+
+import (
+	"strings"
+	"testing"
+
+	"bookclubbot.com/main/controllers"
+	"bookclubbot.com/main/models"
+)
+
+func TestAnnouncementEmbed(t *testing.T) {
+	embed := announcementEmbed(controllers.Announcement{
+		Meetup:     models.ScheduleEntry{Id: "s1", Date: "October 10, 2026", Kind: models.MeetingHybrid, VideoLink: "https://meet.example.com/club"},
+		Book:       models.BookEntry{Id: "book-1", Name: "The Hobbit", Author: "J.R.R. Tolkien"},
+		Cafe:       models.CafeEntry{Id: "cafe-1", Name: "Hot Java", Link: "https://maps.example.com/hot-java"},
+		Part:       2,
+		Parts:      3,
+		TargetPage: 207,
+	})
+
+	fields := map[string]string{}
+	for _, field := range embed.Fields {
+		fields[field.Name] = field.Value
+	}
+	if fields["🎯 Reading Target"] != "Read through page 207 (part 2 of 3)" {
+		t.Errorf("Unexpected reading target %q", fields["🎯 Reading Target"])
+	}
+	if !strings.Contains(fields["📍 Meeting Location"], "Hot Java") {
+		t.Errorf("Expected the cafe to be shown, got %q", fields["📍 Meeting Location"])
+	}
+	if !strings.Contains(fields["💻 Join Online"], "meet.example.com") {
+		t.Errorf("Expected the video link to be shown, got %q", fields["💻 Join Online"])
+	}
+	if !strings.Contains(embed.Description, "October 10, 2026") {
+		t.Errorf("Expected the date in the description, got %q", embed.Description)
+	}
+}
+
+func TestDescribeReadingTarget(t *testing.T) {
+	cases := []struct {
+		announcement controllers.Announcement
+		expected     string
+	}{
+		{controllers.Announcement{Part: 1, Parts: 1}, "Finish the book"},
+		{controllers.Announcement{Part: 3, Parts: 3, TargetPage: 310}, "Finish the book (page 310)"},
+		{controllers.Announcement{Part: 1, Parts: 2}, "Part 1 of 2"},
+	}
+	for _, c := range cases {
+		if got := describeReadingTarget(c.announcement); got != c.expected {
+			t.Errorf("Expected %q, got %q", c.expected, got)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/controllers"
+	"bookclubbot.com/main/models"
+)
+
+const attendancePickerPrefix = "attendance_"
+
+// Discord select menus allow at most 25 values.
+const maxAttendees = 25
+
+// The widest bar in a turnout chart, in characters.
+const turnoutChartWidth = 20
+
+// HandleAttendance shows organizers a member picker for who came to a meetup, filled in
+// with the current record or, before one exists, the members who said they were going.
+func HandleAttendance(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	meetup, err := t.GetScheduleById(i.ApplicationCommandData().GetOption("meetup").StringValue())
+	if err != nil {
+		return respondEphemeral(s, i, "That meetup isn't on the schedule. Pick one from the suggestions.")
+	}
+
+	defaults := []discordgo.SelectMenuDefaultValue{}
+	if record, found := controllers.GetAttendance(t, meetup.Id); found {
+		for _, userId := range record.Attendees {
+			defaults = append(defaults, discordgo.SelectMenuDefaultValue{ID: userId, Type: discordgo.SelectMenuDefaultValueUser})
+		}
+	} else {
+		for _, r := range t.GetRSVPs(meetup.Id) {
+			if r.Status == models.RSVPGoing {
+				defaults = append(defaults, discordgo.SelectMenuDefaultValue{ID: r.UserId, Type: discordgo.SelectMenuDefaultValueUser})
+			}
+		}
+	}
+	defaults = defaults[:min(len(defaults), maxAttendees)]
+
+	minValues := 0
+	err = respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Who came to **%s**?", describeMeetup(t, meetup)),
+			Flags:   discordgo.MessageFlagsEphemeral,
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.SelectMenu{
+							MenuType:      discordgo.UserSelectMenu,
+							CustomID:      attendancePickerPrefix + meetup.Id,
+							Placeholder:   "Members who came",
+							MinValues:     &minValues,
+							MaxValues:     maxAttendees,
+							DefaultValues: defaults,
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to send attendance picker: %v", err)
+	}
+	return nil
+}
+
+func HandleAttendancePicker(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	d := i.MessageComponentData()
+	scheduleId := strings.TrimPrefix(d.CustomID, attendancePickerPrefix)
+	err := controllers.RecordAttendance(t, scheduleId, d.Values, time.Now())
+	if err != nil {
+		return updateEphemeralPrompt(s, i, fmt.Sprintf("Unable to record attendance: %v", err))
+	}
+	meetup, _ := t.GetScheduleById(scheduleId)
+	return updateEphemeralPrompt(s, i, fmt.Sprintf("✅ Recorded %d members at %s.", len(d.Values), describeMeetup(t, meetup)))
+}
+
+func HandleStats(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	if len(t.Attendance) == 0 {
+		return respondEphemeral(s, i, "No attendance has been recorded yet. Organizers can record it with /attendance.")
+	}
+	if i.ApplicationCommandData().Options[0].Name == "club" {
+		return respondEphemeral(s, i, describeClubStats(t, controllers.ComputeClubStats(t)))
+	}
+	return respondEphemeral(s, i, describeMemberStats(t, controllers.ComputeMemberStats(t, interactionUserId(i))))
+}
+
+func describeMemberStats(t *models.ClubTable, stats controllers.MemberStats) string {
+	lines := []string{
+		"**📊 Your Book Club Stats**",
+		fmt.Sprintf("🙋 Came to %d of %d meetups", stats.Attended, stats.Meetups),
+		fmt.Sprintf("🔥 Current streak: %d (longest: %d)", stats.CurrentStreak, stats.LongestStreak),
+		fmt.Sprintf("📚 Books finished: %d", stats.BooksFinished),
+	}
+	if cafe, err := t.GetCafeById(stats.FavoriteCafeId); err == nil {
+		lines = append(lines, fmt.Sprintf("☕️ Favorite cafe: %s", cafe.Name))
+	}
+	lines = append(lines, "", "**Meetups attended by month**", turnoutChart(stats.Monthly))
+	return strings.Join(lines, "\n")
+}
+
+func describeClubStats(t *models.ClubTable, stats controllers.ClubStats) string {
+	lines := []string{
+		"**📊 Club Stats**",
+		fmt.Sprintf("🗓️ %d meetups recorded, averaging %.1f members", stats.Meetups, stats.AverageTurnout),
+		fmt.Sprintf("📚 Books finished: %d", stats.BooksFinished),
+	}
+	if cafe, err := t.GetCafeById(stats.MostAttendedCafeId); err == nil {
+		lines = append(lines, fmt.Sprintf("☕️ Most attended cafe: %s", cafe.Name))
+	}
+	if stats.TopStreakUserId != "" {
+		lines = append(lines, fmt.Sprintf("🔥 Longest current streak: <@%s> with %d", stats.TopStreakUserId, stats.TopStreak))
+	}
+	lines = append(lines, "", "**Turnout by month**", turnoutChart(stats.Monthly))
+	return strings.Join(lines, "\n")
+}
+
+// turnoutChart draws monthly counts as a bar chart in a code block, scaled to the busiest month.
+func turnoutChart(months []controllers.MonthlyTurnout) string {
+	busiest := 0
+	for _, m := range months {
+		busiest = max(busiest, m.Count)
+	}
+	rows := []string{}
+	for _, m := range months {
+		width := 0
+		if busiest > 0 {
+			width = (m.Count*turnoutChartWidth + busiest - 1) / busiest
+		}
+		rows = append(rows, fmt.Sprintf("%s %s %d", m.Month.Format("Jan 2006"), strings.Repeat("█", width), m.Count))
+	}
+	return "```\n" + strings.Join(rows, "\n") + "\n```"
+}
@@ -0,0 +1,28 @@
+package views
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"bookclubbot.com/main/controllers"
+)
+
+func TestTurnoutChart_ScalesToBusiestMonth(t *testing.T) {
+	chart := turnoutChart([]controllers.MonthlyTurnout{
+		{Month: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), Count: 10},
+		{Month: time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC), Count: 0},
+		{Month: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), Count: 5},
+	})
+
+	expected := []string{
+		"Jul 2026 " + strings.Repeat("█", turnoutChartWidth) + " 10",
+		"Aug 2026  0",
+		"Sep 2026 " + strings.Repeat("█", turnoutChartWidth/2) + " 5",
+	}
+	for _, row := range expected {
+		if !strings.Contains(chart, row) {
+			t.Errorf("Expected %q in:\n%s", row, chart)
+		}
+	}
+}
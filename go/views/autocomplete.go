@@ -0,0 +1,142 @@
+package views
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/controllers"
+	"bookclubbot.com/main/models"
+)
+
+// Discord shows at most 25 suggestions.
+const maxAutocompleteChoices = 25
+
+// AutocompleteSource suggests values for a command option from what the member typed so far.
+type AutocompleteSource func(t *models.ClubTable, query string) []*discordgo.ApplicationCommandOptionChoice
+
+// bookAutocomplete suggests books matching the filter. Choices carry the book ID, so
+// handlers should resolve them with GetBookByIdOrTitle.
+func bookAutocomplete(filter func(models.BookEntry) bool) AutocompleteSource {
+	return func(t *models.ClubTable, query string) []*discordgo.ApplicationCommandOptionChoice {
+		books := []models.BookEntry{}
+		names := []string{}
+		for _, book := range t.BookPool {
+			if filter(book) {
+				books = append(books, book)
+				names = append(names, book.Name+" "+book.Author)
+			}
+		}
+		choices := []*discordgo.ApplicationCommandOptionChoice{}
+		for _, index := range controllers.FuzzySearch(query, names, maxAutocompleteChoices) {
+			book := books[index]
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  truncate(fmt.Sprintf("%s by %s", book.Name, book.Author), 100),
+				Value: book.Id,
+			})
+		}
+		return choices
+	}
+}
+
+// cafeAutocomplete suggests cafes from the pool. Choices carry the cafe ID.
+func cafeAutocomplete() AutocompleteSource {
+	return func(t *models.ClubTable, query string) []*discordgo.ApplicationCommandOptionChoice {
+		names := []string{}
+		for _, cafe := range t.CafePool {
+			names = append(names, cafe.Name)
+		}
+		choices := []*discordgo.ApplicationCommandOptionChoice{}
+		for _, index := range controllers.FuzzySearch(query, names, maxAutocompleteChoices) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  truncate(t.CafePool[index].Name, 100),
+				Value: t.CafePool[index].Id,
+			})
+		}
+		return choices
+	}
+}
+
+// meetupAutocomplete suggests meetups on the schedule matching the filter. Choices carry
+// the schedule ID.
+func meetupAutocomplete(filter func(models.ScheduleEntry) bool) AutocompleteSource {
+	return func(t *models.ClubTable, query string) []*discordgo.ApplicationCommandOptionChoice {
+		meetups := []models.ScheduleEntry{}
+		names := []string{}
+		for _, meetup := range t.Schedule {
+			if filter(meetup) {
+				meetups = append(meetups, meetup)
+				names = append(names, describeMeetup(t, meetup))
+			}
+		}
+		choices := []*discordgo.ApplicationCommandOptionChoice{}
+		for _, index := range controllers.FuzzySearch(query, names, maxAutocompleteChoices) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  truncate(names[index], 100),
+				Value: meetups[index].Id,
+			})
+		}
+		return choices
+	}
+}
+
+func anyBook(book models.BookEntry) bool        { return true }
+func isUnreadBook(book models.BookEntry) bool   { return !book.Read }
+func isArchivedBook(book models.BookEntry) bool { return book.Archived }
+
+func anyMeetup(meetup models.ScheduleEntry) bool { return true }
+func isPastMeetup(meetup models.ScheduleEntry) bool {
+	return controllers.MeetupHasHappened(meetup, time.Now())
+}
+
+// focusedOption finds the option the member is typing in, looking inside subcommands.
+func focusedOption(options []*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	for _, option := range options {
+		if option.Focused {
+			return option
+		}
+		if focused := focusedOption(option.Options); focused != nil {
+			return focused
+		}
+	}
+	return nil
+}
+
+func makeAutocompleteHandler(cmds []SlashCommand) func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	cmdMap := make(map[string]SlashCommand, len(cmds))
+	for _, cmd := range cmds {
+		cmdMap[cmd.Name] = cmd
+	}
+
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		data := i.ApplicationCommandData()
+		option := focusedOption(data.Options)
+		if option == nil {
+			log.Printf("No focused option for autocomplete on command: %s", data.Name)
+			return
+		}
+		source, ok := cmdMap[data.Name].Autocomplete[option.Name]
+		if !ok {
+			log.Printf("No autocomplete source for option %s on command: %s", option.Name, data.Name)
+			return
+		}
+
+		t, err := readClubTable()
+		if err != nil {
+			log.Printf("Error loading club table for autocomplete on command %s: %v", data.Name, err)
+			return
+		}
+		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+			Data: &discordgo.InteractionResponseData{
+				// Options of any type can be focused, so the raw value is used as the query.
+				Choices: source(&t, fmt.Sprint(option.Value)),
+			},
+		})
+		if err != nil {
+			fmt.Println("Error handling autocomplete for ", data.Name, ":", err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package views
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/models"
+)
+
+func TestBookAutocomplete(t *testing.T) {
+	table := models.ClubTable{
+		BookPool: []models.BookEntry{
+			{Id: "book-1", Name: "The Hobbit", Author: "J.R.R. Tolkien"},
+			{Id: "book-2", Name: "Dune", Author: "Frank Herbert", Read: true},
+			{Id: "book-3", Name: "Dune Messiah", Author: "Frank Herbert"},
+		},
+	}
+
+	choices := bookAutocomplete(isUnreadBook)(&table, "dune")
+	if len(choices) != 1 || choices[0].Value != "book-3" {
+		t.Errorf("Expected only the unread Dune book, got %+v", choices)
+	}
+	choices = bookAutocomplete(anyBook)(&table, "tolkein")
+	if len(choices) != 1 || choices[0].Name != "The Hobbit by J.R.R. Tolkien" {
+		t.Errorf("Expected an author typo to find The Hobbit, got %+v", choices)
+	}
+
+	// The chosen value resolves back to the book, and so does a typed title.
+	for _, value := range []string{"book-1", "the hobbit"} {
+		book, err := table.GetBookByIdOrTitle(value)
+		if err != nil || book.Id != "book-1" {
+			t.Errorf("Could not resolve %q: %v", value, err)
+		}
+	}
+}
+
+func TestCafeAutocomplete(t *testing.T) {
+	table := models.ClubTable{
+		CafePool: []models.CafeEntry{{Id: "cafe-1", Name: "Hot Java"}, {Id: "cafe-2", Name: "Cyclops Coffee"}},
+	}
+	choices := cafeAutocomplete()(&table, "cyclop")
+	if len(choices) != 1 || choices[0].Value != "cafe-2" {
+		t.Errorf("Expected Cyclops Coffee, got %+v", choices)
+	}
+}
+
+func TestFocusedOption(t *testing.T) {
+	options := []*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: "sub", Options: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "other"},
+			{Name: "book", Focused: true},
+		}},
+	}
+	if got := focusedOption(options); got == nil || got.Name != "book" {
+		t.Errorf("Expected the nested book option to be focused, got %+v", got)
+	}
+}
+
+func TestMeetupAutocomplete_OnlyPastMeetupsForAttendance(t *testing.T) {
+	table := models.ClubTable{
+		Schedule: []models.ScheduleEntry{
+			{Id: "past", Date: "January 3, 2026"},
+			{Id: "future", Date: "January 3, 2099"},
+			{Id: "undated"},
+		},
+	}
+
+	choices := meetupAutocomplete(isPastMeetup)(&table, "")
+	if len(choices) != 1 || choices[0].Value != "past" {
+		t.Errorf("Expected only the past meetup, got %+v", choices)
+	}
+	if choices := meetupAutocomplete(anyMeetup)(&table, ""); len(choices) != 3 {
+		t.Errorf("Expected every meetup, got %+v", choices)
+	}
+}
@@ -1,21 +1,39 @@
 package views
 
 import (
-	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/controllers"
+	"bookclubbot.com/main/models"
 )
 
 type SlashCommand struct {
 	discordgo.ApplicationCommand
-	Handler func(s *discordgo.Session, i *discordgo.InteractionCreate) error
+	Handler HandlerFunc
+	// What a member needs to be allowed to run the command. Empty lets everyone run it.
+	Capability models.Capability
+	// Runs before the handler, after the standard middleware every handler gets.
+	Middleware []Middleware
+	// Suggestions for options declared with Autocomplete, keyed by option name.
+	Autocomplete map[string]AutocompleteSource
 }
 
 type ModalHandler struct {
 	CustomIdPrefix string
-	Handler        func(s *discordgo.Session, i *discordgo.InteractionCreate) error
+	Handler        HandlerFunc
+	Capability     models.Capability
+	Middleware     []Middleware
+}
+
+type ComponentHandler struct {
+	CustomIdPrefix string
+	Handler        HandlerFunc
+	Capability     models.Capability
+	Middleware     []Middleware
 }
 
 func getSlashCommands() []SlashCommand {
@@ -26,7 +44,8 @@ func getSlashCommands() []SlashCommand {
 				Name:        "recommend-a-book",
 				Description: "Submit a book recommendation",
 			},
-			Handler: HandleRecommendABook,
+			Capability: models.CapabilityRecommend,
+			Handler:    HandleRecommendABook,
 		},
 		{
 			ApplicationCommand: discordgo.ApplicationCommand{
@@ -40,51 +59,662 @@ func getSlashCommands() []SlashCommand {
 				Name:        "recommend-a-cafe",
 				Description: "Submit a cafe recommendation",
 			},
-			Handler: HandleRecommendACafe,
+			Capability: models.CapabilityRecommend,
+			Handler:    HandleRecommendACafe,
 		},
 		{
 			ApplicationCommand: discordgo.ApplicationCommand{
 				Name:        "make-announcement",
-				Description: "View the list of recommended cafes",
+				Description: "Preview and post this week's announcement",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionChannel,
+						Name:         "channel",
+						Description:  "Where to post it (defaults to the announcement channel)",
+						ChannelTypes: announcementChannelTypes,
+					},
+				},
 			},
-			Handler: DiscordResponseWrapper(HandleSchedule),
+			Capability: models.CapabilityPlanSchedule,
+			Handler:    withClubTable(HandleMakeAnnouncement),
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "set-announcement-channel",
+				Description: "Choose where announcements are posted",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionChannel,
+						Name:         "channel",
+						Description:  "The announcement channel",
+						Required:     true,
+						ChannelTypes: announcementChannelTypes,
+					},
+				},
+			},
+			Capability: models.CapabilityAdmin,
+			Handler:    withClubTable(HandleSetAnnouncementChannel),
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "schedule-announcements",
+				Description: "Post the weekly announcement automatically",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "day",
+						Description: "The day to post on",
+						Required:    true,
+						Choices:     weekdayChoices,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "time",
+						Description: "The local time to post at, like 18:00 (default 18:00)",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "timezone",
+						Description: "The club's time zone, like America/Chicago",
+					},
+				},
+			},
+			Capability: models.CapabilityAdmin,
+			Handler:    withClubTable(HandleScheduleAnnouncements),
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "reminders",
+				Description: "Get meetup reminders by DM",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "on",
+						Description: "Get a DM before each meetup",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "off",
+						Description: "Stop getting DMs before meetups",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "offsets",
+						Description: "Choose how long before each meetup you get a DM",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "before",
+								Description: "Offsets like 1d, 2h, 30m",
+								Required:    true,
+							},
+						},
+					},
+				},
+			},
+			Handler: withClubTable(HandleReminders),
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "set-meetup-reminders",
+				Description: "Choose where and when meetup reminders are posted",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionChannel,
+						Name:         "channel",
+						Description:  "Where reminders are posted",
+						ChannelTypes: announcementChannelTypes,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionRole,
+						Name:        "role",
+						Description: "The role to mention in reminders",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "before",
+						Description: "Offsets like 1d, 2h (default 1d, 2h)",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "meetup-time",
+						Description: "The local time meetups start, like 14:00",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "off",
+						Description: "Stop posting reminders in the channel",
+					},
+				},
+			},
+			Capability: models.CapabilityAdmin,
+			Handler:    withClubTable(HandleSetMeetupReminders),
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "who-is-coming",
+				Description: "See who RSVP'd to a meetup",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "meetup",
+						Description:  "The meetup to check (default: the next one)",
+						Autocomplete: true,
+					},
+				},
+			},
+			Autocomplete: map[string]AutocompleteSource{
+				"meetup": meetupAutocomplete(anyMeetup),
+			},
+			Handler: withClubTable(HandleWhoIsComing),
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "set-cafe-capacity",
+				Description: "Set how many members a cafe can seat",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "cafe",
+						Description:  "The cafe to update",
+						Required:     true,
+						Autocomplete: true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "capacity",
+						Description: "How many members it seats (0 if unknown)",
+						Required:    true,
+						MinValue:    &minSettingValue,
+					},
+				},
+			},
+			Autocomplete: map[string]AutocompleteSource{
+				"cafe": cafeAutocomplete(),
+			},
+			Capability: models.CapabilityManageCafes,
+			Handler:    withClubTable(HandleSetCafeCapacity),
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "attendance",
+				Description: "Record who came to a meetup",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "meetup",
+						Description:  "The meetup to record",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+			Autocomplete: map[string]AutocompleteSource{
+				"meetup": meetupAutocomplete(isPastMeetup),
+			},
+			Capability: models.CapabilityPlanSchedule,
+			Handler:    readingClubTable(HandleAttendance),
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "stats",
+				Description: "See attendance stats",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "me",
+						Description: "Your streaks, books finished and favorite cafe",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "club",
+						Description: "Turnout, books finished and the most attended cafe",
+					},
+				},
+			},
+			Handler: readingClubTable(HandleStats),
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "meetup-events",
+				Description: "Mirror upcoming meetups to the server's Events tab",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "enabled",
+						Description: "Whether meetups show up as events",
+						Required:    true,
+					},
+				},
+			},
+			Capability: models.CapabilityAdmin,
+			Handler:    HandleMeetupEvents,
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "my-votes",
+				Description: "List the books you voted for",
+			},
+			Handler: readingClubTable(HandleMyVotes),
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "start-book-poll",
+				Description: "Start a ranked-choice poll among the most voted books",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "finalists",
+						Description: "How many books make the ballot (default 5)",
+						MinValue:    &minPollFinalists,
+						MaxValue:    maxPollFinalists,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "hours",
+						Description: "How long voting stays open (default 48, at most 336)",
+						MinValue:    &minPollHours,
+						MaxValue:    maxPollHours,
+					},
+				},
+			},
+			Capability: models.CapabilityPlanSchedule,
+			Handler:    HandleStartBookPoll,
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "set-selection-strategy",
+				Description: "Choose how the next book is picked",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "strategy",
+						Description: "How to pick the next book",
+						Required:    true,
+						Choices:     selectionStrategyChoices(),
+					},
+				},
+			},
+			Capability: models.CapabilityAdmin,
+			Handler:    withClubTable(HandleSetSelectionStrategy),
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "set-vote-aging",
+				Description: "Choose how votes age and when inactive books are archived",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "half-life-days",
+						Description: "Days until a vote counts half as much (0 turns decay off)",
+						MinValue:    &minSettingValue,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "window-months",
+						Description: "Only count votes from the last N months (0 counts every vote)",
+						MinValue:    &minSettingValue,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "archive-after-months",
+						Description: "Archive books with no activity for N months (0 never archives)",
+						MinValue:    &minSettingValue,
+					},
+				},
+			},
+			Capability: models.CapabilityAdmin,
+			Handler:    withClubTable(HandleSetVoteAging),
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "set-vote-limit",
+				Description: "Choose how many books each member can vote for at once",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "max-votes",
+						Description: "Books each member can vote for at once (0 means no limit)",
+						Required:    true,
+						MinValue:    &minSettingValue,
+					},
+				},
+			},
+			Capability: models.CapabilityAdmin,
+			Handler:    withClubTable(HandleSetVoteLimit),
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "revive-book",
+				Description: "Bring an archived book recommendation back",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "book",
+						Description:  "The archived book to revive",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+			Autocomplete: map[string]AutocompleteSource{
+				"book": bookAutocomplete(isArchivedBook),
+			},
+			Capability: models.CapabilityPlanSchedule,
+			Handler:    withClubTable(HandleReviveBook),
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "content-warning",
+				Description: "Add a content warning to a recommended book",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "book",
+						Description:  "The book to add a warning to",
+						Required:     true,
+						Autocomplete: true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "warning",
+						Description: "The content to warn about",
+						Required:    true,
+						MaxLength:   100,
+					},
+				},
+			},
+			Autocomplete: map[string]AutocompleteSource{
+				"book": bookAutocomplete(isUnreadBook),
+			},
+			Capability: models.CapabilityRecommend,
+			Handler:    withClubTable(HandleContentWarning),
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "veto",
+				Description: "Anonymously veto a recommended book",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "book",
+						Description:  "The book to veto",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+			Autocomplete: map[string]AutocompleteSource{
+				"book": bookAutocomplete(isUnreadBook),
+			},
+			Capability: models.CapabilityVote,
+			Handler:    withClubTable(HandleVeto),
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "veto-summary",
+				Description: "See vetoes and content warnings",
+			},
+			Capability: models.CapabilityPlanSchedule,
+			Handler:    withClubTable(HandleVetoSummary),
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "set-veto-rules",
+				Description: "Choose how many vetoes members get and when a book is excluded",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "vetoes-per-season",
+						Description: "Vetoes each member gets per season (0 turns vetoes off)",
+						MinValue:    &minSettingValue,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "threshold",
+						Description: "Vetoes it takes to exclude a book (0 never excludes)",
+						MinValue:    &minSettingValue,
+					},
+				},
+			},
+			Capability: models.CapabilityAdmin,
+			Handler:    withClubTable(HandleSetVetoRules),
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "set-recommender-fairness",
+				Description: "Stop the same member's picks from winning back-to-back",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "enabled",
+						Description: "Whether back-to-back picks are prevented",
+						Required:    true,
+					},
+				},
+			},
+			Capability: models.CapabilityAdmin,
+			Handler:    withClubTable(HandleSetRecommenderFairness),
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "book-info",
+				Description: "See the details of a recommended book",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "book",
+						Description:  "The book to look up",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+			Autocomplete: map[string]AutocompleteSource{
+				"book": bookAutocomplete(anyBook),
+			},
+			Handler: readingClubTable(HandleBookInfo),
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "edit-recommendation",
+				Description: "Fix the details of a book you recommended",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "book",
+						Description:  "The book to edit",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+			Autocomplete: map[string]AutocompleteSource{
+				"book": bookAutocomplete(isUnreadBook),
+			},
+			Capability: models.CapabilityRecommend,
+			Handler:    withClubTable(HandleEditRecommendation),
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "withdraw-recommendation",
+				Description: "Take back a book you recommended",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "book",
+						Description:  "The book to withdraw",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+			Autocomplete: map[string]AutocompleteSource{
+				"book": bookAutocomplete(isUnreadBook),
+			},
+			Capability: models.CapabilityRecommend,
+			Handler:    HandleWithdrawRecommendation,
+		},
+		{
+			ApplicationCommand: discordgo.ApplicationCommand{
+				Name:        "permissions",
+				Description: "Choose what members with a role can do",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "grant",
+						Description: "Let members with a role do something",
+						Options:     permissionOptions(),
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "revoke",
+						Description: "Stop members with a role from doing something",
+						Options:     permissionOptions(),
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "list",
+						Description: "See what each role can do",
+					},
+				},
+			},
+			Capability: models.CapabilityAdmin,
+			Handler:    withClubTable(HandlePermissions),
 		},
 	}
 
+	// Discord hides admin commands from members who can't manage the server.
+	// Server admins can still show them to other roles in the integration settings.
+	for n := range commands {
+		if commands[n].Capability == models.CapabilityAdmin {
+			commands[n].DefaultMemberPermissions = &organizerPermissions
+		}
+		if commands[n].Capability != "" {
+			commands[n].Contexts = &guildOnlyContexts
+		}
+	}
 	return commands
 }
 
+// Option bounds are passed to discordgo by pointer.
+var minSettingValue = 0.0
+
+func selectionStrategyChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, strategy := range controllers.SelectionStrategies() {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  strategy.Description(),
+			Value: strategy.Name(),
+		})
+	}
+	return choices
+}
+
+// Limits on how often a member can submit, so a stuck button or a bored member can't flood the club.
+const (
+	recommendationsPerHour = 5
+	votesPerMinute         = 10
+)
+
 func getModalHandlers() []ModalHandler {
 	handlers := []ModalHandler{
 		{
 			CustomIdPrefix: "book_recommendation",
+			Capability:     models.CapabilityRecommend,
 			Handler:        HandleRecommendABookModalResponse,
+			Middleware:     []Middleware{withRateLimit(recommendationsPerHour, time.Hour)},
 		},
 		{
 			CustomIdPrefix: "cafe_recommendation",
-			Handler:        HandleRecommendACafeModalResponse,
+			Capability:     models.CapabilityRecommend,
+			Handler:        withClubTable(HandleRecommendACafeModalResponse),
+			Middleware:     []Middleware{withRateLimit(recommendationsPerHour, time.Hour)},
+		},
+		{
+			CustomIdPrefix: bookEditModalPrefix,
+			Capability:     models.CapabilityRecommend,
+			Handler:        HandleEditRecommendationModalResponse,
+		},
+	}
+	return handlers
+}
+
+func getComponentHandlers() []ComponentHandler {
+	handlers := []ComponentHandler{
+		{
+			CustomIdPrefix: voteButtonPrefix,
+			Capability:     models.CapabilityVote,
+			Handler:        withClubTable(HandleVoteButton),
+			Middleware:     []Middleware{withRateLimit(votesPerMinute, time.Minute)},
+		},
+		{
+			CustomIdPrefix: unvoteButtonPrefix,
+			Capability:     models.CapabilityVote,
+			Handler:        withClubTable(HandleUnvoteButton),
+			Middleware:     []Middleware{withRateLimit(votesPerMinute, time.Minute)},
+		},
+		{
+			CustomIdPrefix: bookDetailsButtonPrefix,
+			Handler:        readingClubTable(HandleBookDetailsButton),
+		},
+		{
+			CustomIdPrefix: bookPollRankPrefix,
+			Capability:     models.CapabilityVote,
+			Handler:        withClubTable(HandleBookPollRank),
+		},
+		{
+			CustomIdPrefix: duplicateVoteButtonPrefix,
+			Capability:     models.CapabilityVote,
+			Handler:        HandleDuplicateVoteButton,
+		},
+		{
+			CustomIdPrefix: duplicateAddButtonPrefix,
+			Capability:     models.CapabilityRecommend,
+			Handler:        HandleDuplicateAddButton,
+		},
+		{
+			CustomIdPrefix: announcementPostPrefix,
+			Capability:     models.CapabilityPlanSchedule,
+			Handler:        HandleAnnouncementPost,
+		},
+		{
+			CustomIdPrefix: announcementCancelPrefix,
+			Handler:        HandleAnnouncementCancel,
+		},
+		{
+			CustomIdPrefix: rsvpButtonPrefix,
+			Handler:        withClubTable(HandleRSVPButton),
+		},
+		{
+			CustomIdPrefix: attendancePickerPrefix,
+			Capability:     models.CapabilityPlanSchedule,
+			Handler:        withClubTable(HandleAttendancePicker),
 		},
 	}
 	return handlers
 }
 
+// handlerMiddleware puts the standard middleware and the capability check ahead of a handler's own middleware.
+func handlerMiddleware(name string, capability models.Capability, middleware []Middleware) []Middleware {
+	chained := standardMiddleware(name)
+	if capability != "" {
+		chained = append(chained, requireCapability(capability))
+	}
+	return append(chained, middleware...)
+}
+
 func makeSlashCommandHandler(cmds []SlashCommand) func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	cmdMap := make(map[string]SlashCommand, len(cmds))
+	handlerMap := make(map[string]HandlerFunc, len(cmds))
 	for _, cmd := range cmds {
-		cmdMap[cmd.Name] = cmd
+		name := "command " + cmd.Name
+		handlerMap[cmd.Name] = chain(cmd.Handler, handlerMiddleware(name, cmd.Capability, cmd.Middleware)...)
 	}
 
 	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		data := i.ApplicationCommandData()
-		if handlerCmd, ok := cmdMap[data.Name]; ok {
-			fmt.Println("Handling slash command:", data.Name)
-			err := handlerCmd.Handler(s, i)
-			if err != nil {
-				// Log the error
-				// In a real application, you might want to send an error response to the user
-				// Here we just log it for simplicity
-				fmt.Println("Error handling command ", data.Name, ":", err)
-			}
+		if handler, ok := handlerMap[data.Name]; ok {
+			handleInteraction(s, i, "command "+data.Name, handler)
 		} else {
 			log.Printf("No handler found for command: %s", data.Name)
 		}
@@ -92,49 +722,63 @@ func makeSlashCommandHandler(cmds []SlashCommand) func(s *discordgo.Session, i *
 }
 
 func makeModalHandler(handlers []ModalHandler) func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	handlerMap := make(map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate) error)
+	handlerMap := make(map[string]HandlerFunc)
 	for _, handler := range handlers {
-		handlerMap[handler.CustomIdPrefix] = handler.Handler
+		name := "modal " + handler.CustomIdPrefix
+		handlerMap[handler.CustomIdPrefix] = chain(handler.Handler, handlerMiddleware(name, handler.Capability, handler.Middleware)...)
 	}
 
 	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		d := i.ModalSubmitData()
 		for CustomIdPrefix, handler := range handlerMap {
 			if strings.HasPrefix(d.CustomID, CustomIdPrefix) {
-				fmt.Println("Handling modal:", CustomIdPrefix)
-				err := handler(s, i)
-				if err != nil {
-					fmt.Println("Error handling modal ", CustomIdPrefix, ":", err)
-				}
+				handleInteraction(s, i, "modal "+CustomIdPrefix, handler)
+				return
+			}
+		}
+	}
+}
+
+func makeComponentHandler(handlers []ComponentHandler) func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	handlerMap := make(map[string]HandlerFunc)
+	for _, handler := range handlers {
+		name := "component " + handler.CustomIdPrefix
+		handlerMap[handler.CustomIdPrefix] = chain(handler.Handler, handlerMiddleware(name, handler.Capability, handler.Middleware)...)
+	}
+
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		d := i.MessageComponentData()
+		for CustomIdPrefix, handler := range handlerMap {
+			if strings.HasPrefix(d.CustomID, CustomIdPrefix) {
+				handleInteraction(s, i, "component "+CustomIdPrefix, handler)
 				return
 			}
 		}
+		log.Printf("No handler found for component: %s", d.CustomID)
 	}
 }
 
-func makeInteractionCreateHandler(commands []SlashCommand, modalHandlers []ModalHandler) func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func makeInteractionCreateHandler(commands []SlashCommand, modalHandlers []ModalHandler, componentHandlers []ComponentHandler) func(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	slashCommandHandler := makeSlashCommandHandler(commands)
+	autocompleteHandler := makeAutocompleteHandler(commands)
 	modalHandler := makeModalHandler(modalHandlers)
+	componentHandler := makeComponentHandler(componentHandlers)
 	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		switch i.Type {
 		case discordgo.InteractionApplicationCommand:
 			slashCommandHandler(s, i)
 		case discordgo.InteractionModalSubmit:
 			modalHandler(s, i)
+		case discordgo.InteractionMessageComponent:
+			componentHandler(s, i)
+		case discordgo.InteractionApplicationCommandAutocomplete:
+			autocompleteHandler(s, i)
 		}
 	}
 }
+
+// RegisterInteractionCreateHandler routes interactions to their handlers. Commands are
+// registered with Discord separately by SyncCommands.
 func RegisterInteractionCreateHandler(s *discordgo.Session) {
-	commands := getSlashCommands()
-	modalHandlers := getModalHandlers()
-	s.AddHandler(makeInteractionCreateHandler(commands, modalHandlers))
-	// Register the command with Discord
-	// NOTE: Passing "" as the GuildID makes it a Global Command (can take 1 hour to appear).
-	// For testing, replace "" with your specific Guild ID (Server ID) for instant updates.
-	for _, cmd := range commands {
-		_, err := s.ApplicationCommandCreate(s.State.User.ID, "", &cmd.ApplicationCommand)
-		if err != nil {
-			log.Panicf("Cannot create slash command %s: %v", cmd.Name, err)
-		}
-	}
+	s.AddHandler(makeInteractionCreateHandler(getSlashCommands(), getModalHandlers(), getComponentHandlers()))
 }
@@ -0,0 +1,206 @@
+package views
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+type CommandSyncAction string
+
+const (
+	CommandCreate CommandSyncAction = "create"
+	CommandUpdate CommandSyncAction = "update"
+	CommandDelete CommandSyncAction = "delete"
+)
+
+// CommandChange is one step needed to make Discord's commands match ours.
+type CommandChange struct {
+	Action CommandSyncAction
+	Name   string
+	// The registered command's ID, for updates and deletes.
+	Id      string
+	Command *discordgo.ApplicationCommand
+	// The guild the command is registered to, or empty for global commands.
+	GuildId string
+}
+
+func (c CommandChange) String() string {
+	if c.GuildId != "" {
+		return fmt.Sprintf("%s /%s in guild %s", c.Action, c.Name, c.GuildId)
+	}
+	return fmt.Sprintf("%s /%s", c.Action, c.Name)
+}
+
+// SyncCommands makes the commands registered with Discord match getSlashCommands, only touching
+// the ones that differ. Commands are global unless a guild ID is given, which registers them
+// instantly to that guild for development and removes the global ones, so they aren't listed
+// twice there. With dryRun, the plan is returned without applying it.
+func SyncCommands(s *discordgo.Session, appId string, guildId string, dryRun bool) ([]CommandChange, error) {
+	desired := []*discordgo.ApplicationCommand{}
+	for _, cmd := range getSlashCommands() {
+		command := cmd.ApplicationCommand
+		if guildId != "" {
+			// Guild commands only exist in their guild, and Discord ignores their contexts.
+			command.Contexts = nil
+		}
+		desired = append(desired, &command)
+	}
+	if guildId == "" {
+		return syncCommandSet(s, appId, "", desired, dryRun)
+	}
+
+	changes, err := syncCommandSet(s, appId, guildId, desired, dryRun)
+	if err != nil {
+		return changes, err
+	}
+	global_changes, err := syncCommandSet(s, appId, "", nil, dryRun)
+	return append(changes, global_changes...), err
+}
+
+// syncCommandSet makes the commands registered globally or to one guild match the desired ones.
+func syncCommandSet(s *discordgo.Session, appId string, guildId string, desired []*discordgo.ApplicationCommand, dryRun bool) ([]CommandChange, error) {
+	existing, err := s.ApplicationCommands(appId, guildId)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to fetch registered commands: %v", err)
+	}
+
+	changes := planCommandSync(existing, desired)
+	for n := range changes {
+		changes[n].GuildId = guildId
+	}
+	if dryRun {
+		return changes, nil
+	}
+	for _, change := range changes {
+		switch change.Action {
+		case CommandCreate:
+			_, err = s.ApplicationCommandCreate(appId, guildId, change.Command)
+		case CommandUpdate:
+			_, err = s.ApplicationCommandEdit(appId, guildId, change.Id, change.Command)
+		case CommandDelete:
+			err = s.ApplicationCommandDelete(appId, guildId, change.Id)
+		}
+		if err != nil {
+			return changes, fmt.Errorf("Unable to %v: %v", change, err)
+		}
+		log.Println("Synced command:", change)
+	}
+	return changes, nil
+}
+
+// planCommandSync lists the creates, updates and deletes that turn the existing commands into the desired ones.
+func planCommandSync(existing []*discordgo.ApplicationCommand, desired []*discordgo.ApplicationCommand) []CommandChange {
+	existingByName := map[string]*discordgo.ApplicationCommand{}
+	for _, cmd := range existing {
+		existingByName[cmd.Name] = cmd
+	}
+
+	changes := []CommandChange{}
+	wanted := map[string]bool{}
+	for _, cmd := range desired {
+		wanted[cmd.Name] = true
+		current, ok := existingByName[cmd.Name]
+		if !ok {
+			changes = append(changes, CommandChange{Action: CommandCreate, Name: cmd.Name, Command: cmd})
+		} else if commandFingerprint(current) != commandFingerprint(cmd) {
+			changes = append(changes, CommandChange{Action: CommandUpdate, Name: cmd.Name, Id: current.ID, Command: cmd})
+		}
+	}
+	for _, cmd := range existing {
+		if !wanted[cmd.Name] {
+			changes = append(changes, CommandChange{Action: CommandDelete, Name: cmd.Name, Id: cmd.ID})
+		}
+	}
+	return changes
+}
+
+// syncedCommand holds the parts of a command we set, in a form that compares equal
+// whether it was built locally or read back from Discord.
+type syncedCommand struct {
+	Type                     discordgo.ApplicationCommandType
+	Name                     string
+	Description              string
+	DefaultMemberPermissions string
+	DMPermission             bool
+	Contexts                 []discordgo.InteractionContextType
+	Options                  []syncedOption
+}
+
+type syncedOption struct {
+	Type         discordgo.ApplicationCommandOptionType
+	Name         string
+	Description  string
+	Required     bool
+	Autocomplete bool
+	ChannelTypes []discordgo.ChannelType
+	Choices      []string
+	MinValue     string
+	MaxValue     float64
+	MinLength    string
+	MaxLength    int
+	Options      []syncedOption
+}
+
+// The contexts Discord gives commands that don't choose their own.
+var allContexts = []discordgo.InteractionContextType{
+	discordgo.InteractionContextGuild,
+	discordgo.InteractionContextBotDM,
+	discordgo.InteractionContextPrivateChannel,
+}
+
+func commandFingerprint(cmd *discordgo.ApplicationCommand) string {
+	synced := syncedCommand{
+		Type:        cmd.Type,
+		Name:        cmd.Name,
+		Description: cmd.Description,
+		Options:     syncedOptions(cmd.Options),
+	}
+	// Discord fills in the type of chat commands.
+	if synced.Type == 0 {
+		synced.Type = discordgo.ChatApplicationCommand
+	}
+	if cmd.DefaultMemberPermissions != nil {
+		synced.DefaultMemberPermissions = fmt.Sprint(*cmd.DefaultMemberPermissions)
+	}
+	// Commands are allowed in DMs and in every context unless they say otherwise.
+	synced.DMPermission = cmd.DMPermission == nil || *cmd.DMPermission
+	if cmd.Contexts != nil && !slices.Equal(*cmd.Contexts, allContexts) {
+		synced.Contexts = *cmd.Contexts
+	}
+	fingerprint, _ := json.Marshal(synced)
+	return string(fingerprint)
+}
+
+func syncedOptions(options []*discordgo.ApplicationCommandOption) []syncedOption {
+	synced := []syncedOption{}
+	for _, option := range options {
+		s := syncedOption{
+			Type:         option.Type,
+			Name:         option.Name,
+			Description:  option.Description,
+			Required:     option.Required,
+			Autocomplete: option.Autocomplete,
+			ChannelTypes: append([]discordgo.ChannelType{}, option.ChannelTypes...),
+			Choices:      []string{},
+			MaxValue:     option.MaxValue,
+			MaxLength:    option.MaxLength,
+			Options:      syncedOptions(option.Options),
+		}
+		// Choice values come back from Discord as JSON numbers or strings.
+		for _, choice := range option.Choices {
+			s.Choices = append(s.Choices, fmt.Sprintf("%s=%v", choice.Name, choice.Value))
+		}
+		if option.MinValue != nil {
+			s.MinValue = fmt.Sprint(*option.MinValue)
+		}
+		if option.MinLength != nil {
+			s.MinLength = fmt.Sprint(*option.MinLength)
+		}
+		synced = append(synced, s)
+	}
+	return synced
+}
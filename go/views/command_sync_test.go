@@ -0,0 +1,136 @@
+package views
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// registered round-trips commands through JSON, the way Discord hands them back.
+func registered(t *testing.T, commands []*discordgo.ApplicationCommand) []*discordgo.ApplicationCommand {
+	data, err := json.Marshal(commands)
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing := []*discordgo.ApplicationCommand{}
+	err = json.Unmarshal(data, &existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n, cmd := range existing {
+		cmd.ID = "id-" + cmd.Name
+		cmd.Type = discordgo.ChatApplicationCommand
+		cmd.Version = "1"
+		existing[n] = cmd
+	}
+	return existing
+}
+
+func TestPlanCommandSync_NothingToDoWhenRegistered(t *testing.T) {
+	desired := []*discordgo.ApplicationCommand{}
+	for _, cmd := range getSlashCommands() {
+		desired = append(desired, &cmd.ApplicationCommand)
+	}
+
+	changes := planCommandSync(registered(t, desired), desired)
+	if len(changes) != 0 {
+		t.Errorf("Expected no changes, got %v", changes)
+	}
+}
+
+func TestPlanCommandSync_CreatesUpdatesAndDeletes(t *testing.T) {
+	existing := registered(t, []*discordgo.ApplicationCommand{
+		{Name: "view-schedule", Description: "View the book club schedule"},
+		{Name: "my-votes", Description: "Old description"},
+		{Name: "retired-command", Description: "No longer needed"},
+	})
+	desired := []*discordgo.ApplicationCommand{
+		{Name: "view-schedule", Description: "View the book club schedule"},
+		{Name: "my-votes", Description: "List the books you voted for"},
+		{Name: "book-info", Description: "See the details of a recommended book"},
+	}
+
+	changes := planCommandSync(existing, desired)
+	if len(changes) != 3 {
+		t.Fatalf("Expected three changes, got %v", changes)
+	}
+	if changes[0].Action != CommandUpdate || changes[0].Name != "my-votes" || changes[0].Id != "id-my-votes" {
+		t.Errorf("Expected my-votes to be updated, got %v", changes[0])
+	}
+	if changes[1].Action != CommandCreate || changes[1].Name != "book-info" {
+		t.Errorf("Expected book-info to be created, got %v", changes[1])
+	}
+	if changes[2].Action != CommandDelete || changes[2].Id != "id-retired-command" {
+		t.Errorf("Expected retired-command to be deleted, got %v", changes[2])
+	}
+}
+
+func TestPlanCommandSync_NoticesPermissionChanges(t *testing.T) {
+	existing := registered(t, []*discordgo.ApplicationCommand{
+		{Name: "set-veto-rules", Description: "Choose the veto rules"},
+	})
+	desired := []*discordgo.ApplicationCommand{
+		{Name: "set-veto-rules", Description: "Choose the veto rules", DefaultMemberPermissions: &organizerPermissions},
+	}
+
+	changes := planCommandSync(existing, desired)
+	if len(changes) != 1 || changes[0].Action != CommandUpdate {
+		t.Errorf("Expected the command to be updated, got %v", changes)
+	}
+}
+
+func TestPlanCommandSync_NoticesContextChanges(t *testing.T) {
+	existing := registered(t, []*discordgo.ApplicationCommand{
+		{Name: "veto", Description: "Veto a book", Contexts: &allContexts},
+	})
+	desired := []*discordgo.ApplicationCommand{
+		{Name: "veto", Description: "Veto a book", Contexts: &guildOnlyContexts},
+	}
+
+	changes := planCommandSync(existing, desired)
+	if len(changes) != 1 || changes[0].Action != CommandUpdate {
+		t.Errorf("Expected the command to be updated, got %v", changes)
+	}
+
+	dm_permission := false
+	desired = []*discordgo.ApplicationCommand{
+		{Name: "veto", Description: "Veto a book", DMPermission: &dm_permission},
+	}
+	changes = planCommandSync(registered(t, []*discordgo.ApplicationCommand{{Name: "veto", Description: "Veto a book"}}), desired)
+	if len(changes) != 1 || changes[0].Action != CommandUpdate {
+		t.Errorf("Expected the command to be updated, got %v", changes)
+	}
+}
+
+func TestSyncCommands_DevGuildRemovesGlobalCommands(t *testing.T) {
+	s, transport := newRecordingSession()
+	transport.respond = func(request recordedRequest) (int, string) {
+		if request.Method == http.MethodGet && !strings.Contains(request.Path, "/guilds/") {
+			return http.StatusOK, `[{"id": "global-1", "name": "view-schedule", "description": "View the book club schedule"}]`
+		}
+		if request.Method == http.MethodGet {
+			return http.StatusOK, `[]`
+		}
+		return http.StatusOK, `{"id": "1"}`
+	}
+
+	changes, err := SyncCommands(s, "app", "guild", true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	deleted := false
+	for _, change := range changes {
+		if change.Action == CommandDelete && change.Id == "global-1" && change.GuildId == "" {
+			deleted = true
+		}
+		if change.Action == CommandCreate && change.GuildId != "guild" {
+			t.Errorf("Expected commands to be created in the dev guild, got %v", change)
+		}
+	}
+	if !deleted {
+		t.Errorf("Expected the global command to be removed, got %v", changes)
+	}
+}
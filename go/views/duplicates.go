@@ -0,0 +1,188 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/controllers"
+	"bookclubbot.com/main/models"
+)
+
+const (
+	duplicateVoteButtonPrefix = "duplicate_vote_"
+	duplicateAddButtonPrefix  = "duplicate_add_"
+)
+
+// Interaction tokens, and so the buttons on an ephemeral prompt, only work this long.
+const interactionTokenLifetime = 15 * time.Minute
+
+type pendingRecommendation struct {
+	recommendation bookRecommendation
+	expires        time.Time
+}
+
+// Recommendations that look like an existing book wait here until the member decides
+// what to do. The follow-up buttons stop working when the interaction token expires,
+// so these don't need to survive a restart and are dropped once the token has expired.
+var pendingRecommendations = struct {
+	sync.Mutex
+	byId map[string]pendingRecommendation
+}{byId: map[string]pendingRecommendation{}}
+
+func storePendingRecommendation(r bookRecommendation, now time.Time) string {
+	pendingRecommendations.Lock()
+	defer pendingRecommendations.Unlock()
+	for id, pending := range pendingRecommendations.byId {
+		if !now.Before(pending.expires) {
+			delete(pendingRecommendations.byId, id)
+		}
+	}
+	pendingId := models.GenerateId()
+	pendingRecommendations.byId[pendingId] = pendingRecommendation{recommendation: r, expires: now.Add(interactionTokenLifetime)}
+	return pendingId
+}
+
+// takePendingRecommendation removes a pending recommendation, reporting whether it was still waiting.
+func takePendingRecommendation(pendingId string, now time.Time) (bookRecommendation, bool) {
+	pendingRecommendations.Lock()
+	defer pendingRecommendations.Unlock()
+	pending, ok := pendingRecommendations.byId[pendingId]
+	delete(pendingRecommendations.byId, pendingId)
+	if !ok || !now.Before(pending.expires) {
+		return bookRecommendation{}, false
+	}
+	return pending.recommendation, true
+}
+
+func askAboutDuplicateBook(s *discordgo.Session, i *discordgo.InteractionCreate, existing models.BookEntry, r bookRecommendation) error {
+	pendingId := storePendingRecommendation(r, time.Now())
+
+	err := respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("This looks like an existing book: *%s* by %s. Add your vote instead?", existing.Name, existing.Author),
+			Flags:   discordgo.MessageFlagsEphemeral,
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.Button{
+							Label:    "Vote for it",
+							Style:    discordgo.PrimaryButton,
+							Emoji:    &discordgo.ComponentEmoji{Name: voteEmoji},
+							CustomID: duplicateVoteButtonPrefix + existing.Id + "_" + pendingId,
+						},
+						discordgo.Button{
+							Label:    "It's a different book",
+							Style:    discordgo.SecondaryButton,
+							CustomID: duplicateAddButtonPrefix + pendingId,
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to ask about duplicate book: %v", err)
+	}
+	return nil
+}
+
+func HandleDuplicateVoteButton(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	target := strings.TrimPrefix(i.MessageComponentData().CustomID, duplicateVoteButtonPrefix)
+	bookId, pendingId, _ := strings.Cut(target, "_")
+	// Voting instead means the recommendation won't be added.
+	takePendingRecommendation(pendingId, time.Now())
+
+	var book models.BookEntry
+	var vote_err error
+	err := updateClubTable(func(t *models.ClubTable) error {
+		vote_err = controllers.AddVote(t, interactionUserId(i), bookId, time.Now())
+		if errors.Is(vote_err, controllers.ErrVoteCapReached) {
+			return nil
+		}
+		if vote_err != nil {
+			return fmt.Errorf("Unable to record vote: %v", vote_err)
+		}
+		var err error
+		book, err = t.GetBookById(bookId)
+		if err != nil {
+			return fmt.Errorf("Unable to find voted book: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if vote_err != nil {
+		return updateEphemeralPrompt(s, i, fmt.Sprintf("%v. Unvote another book first, then try again.", vote_err))
+	}
+
+	refreshRecommendationTally(s, book)
+	return updateEphemeralPrompt(s, i, fmt.Sprintf("Your vote for *%s* was added! ❤️", book.Name))
+}
+
+func HandleDuplicateAddButton(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	pendingId := strings.TrimPrefix(i.MessageComponentData().CustomID, duplicateAddButtonPrefix)
+
+	r, ok := takePendingRecommendation(pendingId, time.Now())
+	if !ok {
+		return updateEphemeralPrompt(s, i, "This recommendation expired. Please submit it again with /recommend-a-book.")
+	}
+
+	var book models.BookEntry
+	err := updateClubTable(func(t *models.ClubTable) error {
+		var err error
+		book, err = addRecommendedBook(t, r)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = updateEphemeralPrompt(s, i, "Thank you for your recommendation! 📚")
+	if err != nil {
+		return err
+	}
+	return postBookRecommendation(s, book, r)
+}
+
+// updateEphemeralPrompt replaces an ephemeral prompt and its buttons with a plain message.
+func updateEphemeralPrompt(s *discordgo.Session, i *discordgo.InteractionCreate, content string) error {
+	err := respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content,
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to update prompt: %v", err)
+	}
+	return nil
+}
+
+// refreshRecommendationTally updates the vote count shown on a book's recommendation embed.
+func refreshRecommendationTally(s *discordgo.Session, book models.BookEntry) {
+	if book.MessageId == "" {
+		return
+	}
+	msg, err := s.ChannelMessage(book.ChannelId, book.MessageId)
+	if err != nil {
+		log.Println("Could not fetch recommendation to update its tally:", err)
+		return
+	}
+	embeds := []*discordgo.MessageEmbed{}
+	for _, embed := range msg.Embeds {
+		embeds = append(embeds, withVoteTally(embed, book.Votes))
+	}
+	_, err = s.ChannelMessageEditEmbeds(book.ChannelId, book.MessageId, embeds)
+	if err != nil {
+		log.Println("Could not update recommendation tally:", err)
+	}
+}
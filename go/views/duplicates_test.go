@@ -0,0 +1,29 @@
+package views
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPendingRecommendations_ExpireWithTheInteractionToken(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	stale := storePendingRecommendation(bookRecommendation{Title: "Stale"}, now)
+	fresh := storePendingRecommendation(bookRecommendation{Title: "Fresh"}, now.Add(10*time.Minute))
+
+	// Storing another recommendation after the first token expired clears it out.
+	storePendingRecommendation(bookRecommendation{Title: "Later"}, now.Add(interactionTokenLifetime))
+	pendingRecommendations.Lock()
+	_, kept := pendingRecommendations.byId[stale]
+	pendingRecommendations.Unlock()
+	if kept {
+		t.Errorf("Expected the expired recommendation to be dropped")
+	}
+
+	r, ok := takePendingRecommendation(fresh, now.Add(20*time.Minute))
+	if !ok || r.Title != "Fresh" {
+		t.Errorf("Expected the fresh recommendation, got %v %v", r, ok)
+	}
+	if _, ok := takePendingRecommendation(fresh, now.Add(20*time.Minute)); ok {
+		t.Errorf("Expected a recommendation to only be taken once")
+	}
+}
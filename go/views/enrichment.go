@@ -0,0 +1,58 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/models"
+	"bookclubbot.com/main/services"
+)
+
+// How long a recommendation waits on the catalog before being posted without metadata.
+const enrichmentTimeout = 5 * time.Second
+
+var bookEnricher services.BookEnricher
+
+// SetBookEnricher sets the catalog used to look up metadata for new recommendations.
+// Without one, books keep only what the recommender typed.
+func SetBookEnricher(enricher services.BookEnricher) {
+	bookEnricher = enricher
+}
+
+// lookupBookMetadata looks up catalog metadata for a book. It can take a while, so it
+// runs without the club table, and failures are logged and leave the book as it was.
+func lookupBookMetadata(book models.BookEntry) (models.BookMetadata, string, bool) {
+	if bookEnricher == nil {
+		return models.BookMetadata{}, "", false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), enrichmentTimeout)
+	defer cancel()
+	metadata, isbn, err := bookEnricher.Enrich(ctx, book.Name, book.Author, book.ISBN)
+	if err != nil {
+		log.Println("Could not enrich book", book.Name, ":", err)
+		return models.BookMetadata{}, "", false
+	}
+	return metadata, isbn, true
+}
+
+// bookMetadataFields adds a book's catalog metadata to an embed.
+func bookMetadataFields(embed *discordgo.MessageEmbed, book models.BookEntry) {
+	if book.CoverURL != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: book.CoverURL}
+	}
+	if book.PageCount > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Pages", Value: fmt.Sprintf("%d", book.PageCount), Inline: true})
+	}
+	if book.PublishYear > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Published", Value: fmt.Sprintf("%d", book.PublishYear), Inline: true})
+	}
+	if len(book.Subjects) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Subjects", Value: strings.Join(book.Subjects, ", ")})
+	}
+}
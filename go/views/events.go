@@ -0,0 +1,238 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/controllers"
+	"bookclubbot.com/main/models"
+	"bookclubbot.com/main/scheduler"
+)
+
+// meetupEventsJob keeps the guild's scheduled events in step with the schedule.
+func meetupEventsJob(s *discordgo.Session) scheduler.Job {
+	return scheduler.Job{
+		Name: "meetup_events",
+		Schedule: clubSchedule(func(settings models.ClubSettings) string {
+			if settings.EventsGuildId == "" {
+				return ""
+			}
+			return "*/15 * * * *"
+		}),
+		Run: func(due time.Time) error {
+			eventSyncMutex.Lock()
+			defer eventSyncMutex.Unlock()
+
+			var guildId string
+			var changes []controllers.EventChange
+			err := updateClubTable(func(t *models.ClubTable) error {
+				guildId = t.Settings.EventsGuildId
+				changes = planMeetupEvents(t, due)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			applied, sync_err := applyEventChanges(s, guildId, changes)
+			// Events synced before a failure are still recorded.
+			err = updateClubTable(func(t *models.ClubTable) error {
+				recordEventChanges(t, applied)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			return sync_err
+		},
+	}
+}
+
+// eventSyncMutex keeps event syncs from overlapping. Each one plans its changes under the
+// club table lock but makes them in Discord after letting go of it, so two syncs at once
+// could mirror the same meetup twice.
+var eventSyncMutex sync.Mutex
+
+// planMeetupEvents forgets the events of meetups that have started and plans the changes
+// that bring the rest in step with the schedule.
+func planMeetupEvents(t *models.ClubTable, now time.Time) []controllers.EventChange {
+	controllers.ForgetStartedEvents(t, now)
+	return controllers.PlanEventSync(t, now)
+}
+
+// applyEventChanges makes planned changes to a guild's scheduled events, stopping at the
+// first that fails. It returns the changes that were made, for recordEventChanges.
+func applyEventChanges(s *discordgo.Session, guildId string, changes []controllers.EventChange) ([]controllers.EventChange, error) {
+	var applied []controllers.EventChange
+	for _, change := range changes {
+		made, err := applyEventChange(s, guildId, change)
+		if err != nil {
+			return applied, fmt.Errorf("Unable to %v: %v", change, err)
+		}
+		log.Println("Synced:", made)
+		applied = append(applied, made)
+	}
+	return applied, nil
+}
+
+// applyEventChange makes a change in Discord and returns it as made, with the ID of the
+// event it created if any.
+func applyEventChange(s *discordgo.Session, guildId string, change controllers.EventChange) (controllers.EventChange, error) {
+	switch change.Action {
+	case controllers.EventCancel:
+		_, err := s.GuildScheduledEventEdit(guildId, change.EventId, &discordgo.GuildScheduledEventParams{
+			Status: discordgo.GuildScheduledEventStatusCanceled,
+		})
+		// An event deleted or already ended in Discord has nothing left to cancel.
+		if err != nil && !isGoneEvent(s, guildId, change.EventId, err) {
+			return change, err
+		}
+	case controllers.EventUpdate:
+		_, err := s.GuildScheduledEventEdit(guildId, change.EventId, meetupEventParams(change.Event))
+		if err != nil && isGoneEvent(s, guildId, change.EventId, err) {
+			// The event was deleted or ended in Discord, so mirror the meetup again.
+			return applyEventChange(s, guildId, controllers.EventChange{
+				Action:     controllers.EventCreate,
+				ScheduleId: change.ScheduleId,
+				Event:      change.Event,
+			})
+		}
+		if err != nil {
+			return change, err
+		}
+	case controllers.EventCreate:
+		event, err := s.GuildScheduledEventCreate(guildId, meetupEventParams(change.Event))
+		if err != nil {
+			return change, err
+		}
+		change.EventId = event.ID
+	}
+	return change, nil
+}
+
+// recordEventChanges records the changes applyEventChanges made in the club table.
+func recordEventChanges(t *models.ClubTable, applied []controllers.EventChange) {
+	for _, change := range applied {
+		if change.Action == controllers.EventCancel {
+			controllers.ForgetEvent(t, change.ScheduleId)
+		} else {
+			controllers.RecordEvent(t, change.EventId, change.Event)
+		}
+	}
+}
+
+func meetupEventParams(event controllers.MeetupEvent) *discordgo.GuildScheduledEventParams {
+	params := &discordgo.GuildScheduledEventParams{
+		Name:               truncate(event.Name, 100),
+		Description:        truncate(event.Description, 1000),
+		ScheduledStartTime: &event.Start,
+		ScheduledEndTime:   &event.End,
+		PrivacyLevel:       discordgo.GuildScheduledEventPrivacyLevelGuildOnly,
+	}
+	if event.VoiceChannelId != "" {
+		params.EntityType = discordgo.GuildScheduledEventEntityTypeVoice
+		params.ChannelID = event.VoiceChannelId
+	} else {
+		params.EntityType = discordgo.GuildScheduledEventEntityTypeExternal
+		params.EntityMetadata = &discordgo.GuildScheduledEventEntityMetadata{Location: truncate(event.Location, 100)}
+	}
+	return params
+}
+
+// isGoneEvent reports whether an edit failed because the event was deleted, cancelled or
+// completed. Discord refuses edits to cancelled and completed events with a 400, so the
+// event is looked up to tell those apart from edits it rejected for other reasons.
+func isGoneEvent(s *discordgo.Session, guildId string, eventId string, err error) bool {
+	if isNotFound(err) {
+		return true
+	}
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) || restErr.Response == nil || restErr.Response.StatusCode != http.StatusBadRequest {
+		return false
+	}
+	event, err := s.GuildScheduledEvent(guildId, eventId, false)
+	if err != nil {
+		return isNotFound(err)
+	}
+	return event.Status == discordgo.GuildScheduledEventStatusCanceled || event.Status == discordgo.GuildScheduledEventStatusCompleted
+}
+
+func isNotFound(err error) bool {
+	var restErr *discordgo.RESTError
+	return errors.As(err, &restErr) && restErr.Response != nil && restErr.Response.StatusCode == http.StatusNotFound
+}
+
+// HandleMeetupEvents turns mirroring meetups to the Events tab on or off, syncing right away.
+// Turning it off cancels the events already mirrored.
+func HandleMeetupEvents(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	enabled := i.ApplicationCommandData().GetOption("enabled").BoolValue()
+	// Syncing makes a request per event, which can outlast the time Discord allows for a response.
+	err := deferResponse(s, i)
+	if err != nil {
+		return err
+	}
+	eventSyncMutex.Lock()
+	defer eventSyncMutex.Unlock()
+
+	var guildId string
+	var changes []controllers.EventChange
+	err = updateClubTable(func(t *models.ClubTable) error {
+		guildId = t.Settings.EventsGuildId
+		if enabled {
+			guildId = i.GuildID
+			t.Settings.EventsGuildId = guildId
+		}
+		if guildId == "" {
+			return nil
+		}
+		now := time.Now()
+		controllers.ForgetStartedEvents(t, now)
+		// Turning mirroring off plans a cancel for every event. The setting is only cleared
+		// once they are all cancelled, so none are orphaned if Discord refuses some.
+		planned := *t
+		planned.Settings.EventsGuildId = guildId
+		if !enabled {
+			planned.Settings.EventsGuildId = ""
+		}
+		changes = controllers.PlanEventSync(&planned, now)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if guildId == "" {
+		return respondEphemeral(s, i, "Meetups aren't being mirrored to the Events tab.")
+	}
+
+	applied, sync_err := applyEventChanges(s, guildId, changes)
+	err = updateClubTable(func(t *models.ClubTable) error {
+		recordEventChanges(t, applied)
+		if !enabled && sync_err == nil {
+			t.Settings.EventsGuildId = ""
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if sync_err != nil {
+		log.Println(sync_err)
+		return respondEphemeral(s, i, "Unable to sync meetups to the Events tab. Check that I have the Manage Events permission.")
+	}
+	counts := map[controllers.EventSyncAction]int{}
+	for _, change := range applied {
+		counts[change.Action]++
+	}
+	if !enabled {
+		return respondEphemeral(s, i, fmt.Sprintf("Stopped mirroring meetups to the Events tab and cancelled %d events.", counts[controllers.EventCancel]))
+	}
+	return respondEphemeral(s, i, fmt.Sprintf(
+		"📅 Upcoming meetups are mirrored to the Events tab (%d created, %d updated, %d cancelled).",
+		counts[controllers.EventCreate], counts[controllers.EventUpdate], counts[controllers.EventCancel],
+	))
+}
@@ -0,0 +1,146 @@
+package views
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/models"
+)
+
+// syncEvents syncs a table's events the way meetupEventsJob does, without the club table file.
+func syncEvents(s *discordgo.Session, t *models.ClubTable, now time.Time) error {
+	applied, err := applyEventChanges(s, t.Settings.EventsGuildId, planMeetupEvents(t, now))
+	recordEventChanges(t, applied)
+	return err
+}
+
+func TestSyncMeetupEvents_CreatesThenCancels(t *testing.T) {
+	s, transport := newRecordingSession()
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	table := models.ClubTable{
+		Settings: models.ClubSettings{EventsGuildId: "guild-1", TimeZone: "UTC"},
+		Schedule: []models.ScheduleEntry{{Id: "s1", Date: "October 24, 2026", CafeId: "c1"}},
+		CafePool: []models.CafeEntry{{Id: "c1", Name: "Bean There", Link: "https://maps.example/bean"}},
+	}
+
+	err := syncEvents(s, &table, now)
+	if err != nil {
+		t.Fatalf("Internal error %v", err)
+	}
+	requests := transport.recorded()
+	if len(requests) != 1 || requests[0].Method != "POST" || requests[0].Path != "/api/v9/guilds/guild-1/scheduled-events" {
+		t.Fatalf("Expected the event to be created, got %v", requests)
+	}
+	metadata, _ := requests[0].Body["entity_metadata"].(map[string]any)
+	if metadata["location"] != "Bean There · https://maps.example/bean" {
+		t.Errorf("Expected the cafe as the event location, got %v", requests[0].Body)
+	}
+	if len(table.ScheduledEvents) != 1 || table.ScheduledEvents[0].EventId != "1" {
+		t.Fatalf("Expected the event to be recorded, got %v", table.ScheduledEvents)
+	}
+
+	// Syncing again without changes leaves Discord alone.
+	syncEvents(s, &table, now)
+	if len(transport.recorded()) != 1 {
+		t.Errorf("Expected no requests for an unchanged schedule, got %v", transport.recorded()[1:])
+	}
+
+	table.Schedule = nil
+	syncEvents(s, &table, now)
+	requests = transport.recorded()
+	if len(requests) != 2 || requests[1].Method != "PATCH" || requests[1].Body["status"] != float64(4) {
+		t.Errorf("Expected the event to be cancelled, got %v", requests)
+	}
+	if len(table.ScheduledEvents) != 0 {
+		t.Errorf("Expected the cancelled event to be forgotten, got %v", table.ScheduledEvents)
+	}
+}
+
+// endedEventTransport refuses edits to event 1 the way Discord does once it has ended.
+func endedEventTransport(request recordedRequest) (int, string) {
+	switch {
+	case request.Method == http.MethodPatch && request.Path == "/api/v9/guilds/guild-1/scheduled-events/1":
+		return http.StatusBadRequest, `{"code": 50035, "message": "Invalid Form Body"}`
+	case request.Method == http.MethodGet:
+		return http.StatusOK, `{"id": "1", "status": 3}`
+	}
+	return http.StatusOK, `{"id": "2"}`
+}
+
+func TestSyncMeetupEvents_TreatsEndedEventsAsGone(t *testing.T) {
+	s, transport := newRecordingSession()
+	transport.respond = endedEventTransport
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	table := models.ClubTable{
+		Settings:        models.ClubSettings{EventsGuildId: "guild-1", TimeZone: "UTC"},
+		CafePool:        []models.CafeEntry{{Id: "c1", Name: "Bean There", Link: "https://maps.example/bean"}},
+		ScheduledEvents: []models.ScheduledEventEntry{{ScheduleId: "s1", EventId: "1"}},
+	}
+
+	err := syncEvents(s, &table, now)
+	if err != nil {
+		t.Fatalf("Expected cancelling an ended event to succeed, got %v", err)
+	}
+	if len(table.ScheduledEvents) != 0 {
+		t.Errorf("Expected the ended event to be forgotten, got %v", table.ScheduledEvents)
+	}
+
+	table.Schedule = []models.ScheduleEntry{{Id: "s1", Date: "October 24, 2026", CafeId: "c1"}}
+	table.ScheduledEvents = []models.ScheduledEventEntry{{ScheduleId: "s1", EventId: "1", Fingerprint: "stale"}}
+	err = syncEvents(s, &table, now)
+	if err != nil {
+		t.Fatalf("Expected updating an ended event to succeed, got %v", err)
+	}
+	if len(table.ScheduledEvents) != 1 || table.ScheduledEvents[0].EventId != "2" {
+		t.Errorf("Expected a new event to replace the ended one, got %v", table.ScheduledEvents)
+	}
+}
+
+func TestHandleMeetupEvents_StaysOnWhenCancellingFails(t *testing.T) {
+	t.Chdir(t.TempDir())
+	s, transport := newRecordingSession()
+	transport.respond = func(request recordedRequest) (int, string) {
+		if request.Method == http.MethodPatch && strings.Contains(request.Path, "/scheduled-events/") {
+			return http.StatusForbidden, `{"code": 50013, "message": "Missing Permissions"}`
+		}
+		return http.StatusOK, `{"id": "1"}`
+	}
+	err := saveClubTable(models.ClubTable{
+		Settings:        models.ClubSettings{EventsGuildId: "guild-1"},
+		ScheduledEvents: []models.ScheduledEventEntry{{ScheduleId: "s1", EventId: "1"}},
+	})
+	if err != nil {
+		t.Fatalf("Internal error %v", err)
+	}
+	i := newCommandInteraction()
+	i.GuildID = "guild-1"
+	i.Data = discordgo.ApplicationCommandInteractionData{
+		Name: "meetup-events",
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "enabled", Type: discordgo.ApplicationCommandOptionBoolean, Value: false},
+		},
+	}
+
+	err = HandleMeetupEvents(s, i)
+	if err != nil {
+		t.Fatalf("Internal error %v", err)
+	}
+	requests := transport.recorded()
+	if len(requests) < 2 || !strings.HasSuffix(requests[0].Path, "/callback") || requests[0].Body["type"] != float64(discordgo.InteractionResponseDeferredChannelMessageWithSource) {
+		t.Errorf("Expected the response to be deferred before syncing, got %v", requests)
+	}
+	table, err := loadClubTable()
+	if err != nil {
+		t.Fatalf("Internal error %v", err)
+	}
+	if table.Settings.EventsGuildId != "guild-1" {
+		t.Errorf("Expected mirroring to stay on while events are left, got %q", table.Settings.EventsGuildId)
+	}
+	if len(table.ScheduledEvents) != 1 {
+		t.Errorf("Expected the event that couldn't be cancelled to be kept, got %v", table.ScheduledEvents)
+	}
+}
@@ -0,0 +1,197 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Validator checks a submitted form value. Validators only run on non-empty values;
+// use FormField.Required for mandatory fields.
+type Validator func(value string) error
+
+// FormField declares one text input of a modal.
+type FormField struct {
+	CustomID    string
+	Label       string
+	Style       discordgo.TextInputStyle
+	Placeholder string
+	Required    bool
+	MinLength   int
+	MaxLength   int
+	Validators  []Validator
+}
+
+// Form declares a modal once so it can be both sent and decoded. Values are read from
+// and written to struct fields tagged with the field's custom ID, e.g. `form:"book_title"`.
+type Form struct {
+	Title  string
+	Fields []FormField
+}
+
+// FieldError is a problem with one submitted field.
+type FieldError struct {
+	Label   string
+	Message string
+}
+
+// ValidationError lists every field that failed validation.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	problems := []string{}
+	for _, f := range e.Fields {
+		problems = append(problems, fmt.Sprintf("%s %s", f.Label, f.Message))
+	}
+	return strings.Join(problems, "; ")
+}
+
+// Modal builds the modal for this form, prefilled from the tagged fields of values.
+// Values may be nil for an empty form.
+func (f Form) Modal(customId string, values any) *discordgo.InteractionResponseData {
+	prefill := map[string]string{}
+	if values != nil {
+		prefill = formValues(values)
+	}
+	rows := []discordgo.MessageComponent{}
+	for _, field := range f.Fields {
+		rows = append(rows, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.TextInput{
+					CustomID:    field.CustomID,
+					Label:       field.Label,
+					Style:       field.Style,
+					Placeholder: field.Placeholder,
+					Value:       prefill[field.CustomID],
+					Required:    field.Required,
+					MinLength:   field.MinLength,
+					MaxLength:   field.MaxLength,
+				},
+			},
+		})
+	}
+	return &discordgo.InteractionResponseData{
+		CustomID:   customId,
+		Title:      f.Title,
+		Flags:      discordgo.MessageFlagsIsComponentsV2,
+		Components: rows,
+	}
+}
+
+// Decode validates a submitted modal and stores its values into the tagged fields of dst,
+// which must be a pointer to a struct. Fields are matched by custom ID, so their order in
+// the submission doesn't matter. A *ValidationError is returned when any field is invalid.
+func (f Form) Decode(data discordgo.ModalSubmitInteractionData, dst any) error {
+	submitted := map[string]string{}
+	collectTextInputs(data.Components, submitted)
+
+	invalid := &ValidationError{}
+	for _, field := range f.Fields {
+		value := strings.TrimSpace(submitted[field.CustomID])
+		submitted[field.CustomID] = value
+		if message := validateField(field, value); message != "" {
+			invalid.Fields = append(invalid.Fields, FieldError{Label: field.Label, Message: message})
+		}
+	}
+	if len(invalid.Fields) > 0 {
+		return invalid
+	}
+
+	target := reflect.ValueOf(dst)
+	if target.Kind() != reflect.Pointer || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("Form %s must decode into a pointer to a struct, got %T", f.Title, dst)
+	}
+	target = target.Elem()
+	for i := range target.NumField() {
+		tag := target.Type().Field(i).Tag.Get("form")
+		if tag == "" {
+			continue
+		}
+		if target.Field(i).Kind() != reflect.String {
+			return fmt.Errorf("Form field %s must be a string", tag)
+		}
+		target.Field(i).SetString(submitted[tag])
+	}
+	return nil
+}
+
+func validateField(field FormField, value string) string {
+	if value == "" {
+		if field.Required {
+			return "is required."
+		}
+		return ""
+	}
+	length := len([]rune(value))
+	if field.MinLength > 0 && length < field.MinLength {
+		return fmt.Sprintf("must be at least %d characters.", field.MinLength)
+	}
+	if field.MaxLength > 0 && length > field.MaxLength {
+		return fmt.Sprintf("must be at most %d characters.", field.MaxLength)
+	}
+	for _, validator := range field.Validators {
+		if err := validator(value); err != nil {
+			return err.Error()
+		}
+	}
+	return ""
+}
+
+// collectTextInputs finds every text input in a submission, however it is nested.
+func collectTextInputs(components []discordgo.MessageComponent, values map[string]string) {
+	for _, component := range components {
+		switch c := component.(type) {
+		case *discordgo.ActionsRow:
+			collectTextInputs(c.Components, values)
+		case *discordgo.Label:
+			collectTextInputs([]discordgo.MessageComponent{c.Component}, values)
+		case *discordgo.TextInput:
+			values[c.CustomID] = c.Value
+		}
+	}
+}
+
+// formValues reads the tagged string fields of a struct.
+func formValues(values any) map[string]string {
+	prefill := map[string]string{}
+	v := reflect.Indirect(reflect.ValueOf(values))
+	if v.Kind() != reflect.Struct {
+		return prefill
+	}
+	for i := range v.NumField() {
+		tag := v.Type().Field(i).Tag.Get("form")
+		if tag != "" && v.Field(i).Kind() == reflect.String {
+			prefill[tag] = v.Field(i).String()
+		}
+	}
+	return prefill
+}
+
+// respondFormError tells the member what to fix when a form fails validation. Other
+// errors are returned to the caller.
+func respondFormError(s *discordgo.Session, i *discordgo.InteractionCreate, err error) error {
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		return err
+	}
+	lines := []string{"Please fix the following and submit again:"}
+	for _, f := range invalid.Fields {
+		lines = append(lines, fmt.Sprintf("- **%s** %s", f.Label, f.Message))
+	}
+	return respondEphemeral(s, i, strings.Join(lines, "\n"))
+}
+
+// ValidURL accepts absolute http and https links.
+func ValidURL(value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("must be a link starting with http:// or https://.")
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package views
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func textInputRow(customId string, value string) discordgo.MessageComponent {
+	return &discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			&discordgo.TextInput{CustomID: customId, Value: value},
+		},
+	}
+}
+
+func TestForm_DecodeMatchesByCustomId(t *testing.T) {
+	// Fields arrive in a different order than they were declared.
+	data := discordgo.ModalSubmitInteractionData{
+		Components: []discordgo.MessageComponent{
+			textInputRow("book_genre", "Fantasy"),
+			textInputRow("book_author", " J.R.R. Tolkien "),
+			textInputRow("book_title", "The Hobbit"),
+			textInputRow("unknown_field", "ignored"),
+		},
+	}
+	var r bookRecommendation
+	err := bookRecommendationForm.Decode(data, &r)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if r.Title != "The Hobbit" || r.Author != "J.R.R. Tolkien" || r.Genre != "Fantasy" || r.GoodreadsLink != "" {
+		t.Errorf("Unexpected decoded form %+v", r)
+	}
+}
+
+func TestForm_DecodeReportsEveryInvalidField(t *testing.T) {
+	data := discordgo.ModalSubmitInteractionData{
+		Components: []discordgo.MessageComponent{
+			textInputRow("book_title", "   "),
+			textInputRow("book_author", "Someone"),
+			textInputRow("book_goodreads_link", "not a link"),
+		},
+	}
+	var r bookRecommendation
+	err := bookRecommendationForm.Decode(data, &r)
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Expected a validation error, got %v", err)
+	}
+	if len(invalid.Fields) != 2 {
+		t.Errorf("Expected the title and link to be invalid, got %+v", invalid.Fields)
+	}
+	if r.Author != "" {
+		t.Errorf("An invalid submission was partially decoded: %+v", r)
+	}
+}
+
+func TestForm_ModalIsPrefilled(t *testing.T) {
+	modal := editBookRecommendationForm.Modal("book_edit_1", bookRecommendation{Title: "The Hobbit", Author: "J.R.R. Tolkien"})
+	if modal.CustomID != "book_edit_1" || modal.Title != "Edit Book Recommendation" {
+		t.Errorf("Unexpected modal %+v", modal)
+	}
+	if len(modal.Components) != len(bookRecommendationForm.Fields) {
+		t.Fatalf("Expected one row per field, got %d", len(modal.Components))
+	}
+	title := modal.Components[0].(discordgo.ActionsRow).Components[0].(discordgo.TextInput)
+	if title.CustomID != "book_title" || title.Value != "The Hobbit" {
+		t.Errorf("Title was not prefilled: %+v", title)
+	}
+}
+
+func TestForm_DecodeRequiresStructPointer(t *testing.T) {
+	var r bookRecommendation
+	err := cafeRecommendationForm.Decode(discordgo.ModalSubmitInteractionData{
+		Components: []discordgo.MessageComponent{
+			textInputRow("cafe_name", "Hot Java"),
+			textInputRow("google_maps_link", "https://maps.example.com/hot-java"),
+		},
+	}, r)
+	if err == nil {
+		t.Errorf("Expected an error decoding into a non-pointer")
+	}
+}
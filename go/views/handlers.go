@@ -2,9 +2,13 @@ package views
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 
@@ -13,103 +17,80 @@ import (
 )
 
 func HandleSchedule(t models.ClubTable) (string, error) {
-	controllers.SanitizeClubTable(t)
+	err := planSchedule(&t)
+	if err != nil {
+		return "", err
+	}
 
-	err := controllers.AssignDatesToSchedule(t.Schedule)
+	response, err := t.RenderSchedule()
 	if err != nil {
-		return "", fmt.Errorf("Unable to assign dates: %v", err)
+		return "", fmt.Errorf("Unable to render schedule: %v", err)
 	}
 
-	err = controllers.AssignBooksToSchedule(t.BookPool, t.Schedule)
+	return response, nil
+}
+
+// planSchedule dates the schedule and fills in its books and cafes.
+func planSchedule(t *models.ClubTable) error {
+	controllers.SanitizeClubTable(*t)
+	controllers.PruneRSVPs(t)
+
+	err := controllers.AssignDatesToSchedule(t.Schedule)
 	if err != nil {
-		return "", fmt.Errorf("Unable to assign books: %v", err)
+		return fmt.Errorf("Unable to assign dates: %v", err)
 	}
 
-	err = controllers.AssignCafesToSchedule(t.CafePool, t.Schedule)
+	now := time.Now()
+	// Vetoes from past seasons stop counting, so books can come back each season.
+	controllers.ApplyVetoes(t, now)
+	for _, book := range controllers.ArchiveInactiveBooks(t, now) {
+		log.Println("Archived inactive book:", book.Name)
+	}
+
+	strategy, err := controllers.GetSelectionStrategy(t.Settings.SelectionStrategy)
 	if err != nil {
-		return "", fmt.Errorf("Unable to assign cafes: %v", err)
+		return fmt.Errorf("Unable to load selection strategy: %v", err)
 	}
+	strategy = controllers.WithQueuedWinners(strategy)
+	strategy = controllers.WithVoteAging(strategy, t, now)
+	strategy = controllers.WithRecommenderFairness(strategy, t.Settings)
 
-	response, err := t.RenderSchedule()
+	err = controllers.AssignBooksToScheduleWithStrategy(strategy, t.BookPool, t.Schedule)
 	if err != nil {
-		return "", fmt.Errorf("Unable to render schedule: %v", err)
+		return fmt.Errorf("Unable to assign books: %v", err)
 	}
 
-	return response, nil
+	err = controllers.AssignCafesToScheduleWithHeadcounts(t.CafePool, t.Schedule, controllers.ExpectedHeadcounts(t))
+	if err != nil {
+		return fmt.Errorf("Unable to assign cafes: %v", err)
+	}
+	return nil
 }
 
-func DiscordResponseWrapper(handler func(models.ClubTable) (string, error)) func(*discordgo.Session, *discordgo.InteractionCreate) error {
-	return func(s *discordgo.Session, i *discordgo.InteractionCreate) error {
-		t := loadClubTable()
-		response, err := handler(t)
+func DiscordResponseWrapper(handler func(models.ClubTable) (string, error)) HandlerFunc {
+	return withClubTable(func(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+		response, err := handler(*t)
 		if err != nil {
 			return err
 		}
 
-		_, err = s.ChannelMessageSend(i.ChannelID, response)
+		err = respond(s, i, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: response,
+			},
+		})
 		if err != nil {
 			return fmt.Errorf("Unable to send schedule message: %v", err)
 		}
-
-		saveClubTable(t)
 		return nil
-	}
+	})
 }
 
 func HandleRecommendABook(s *discordgo.Session, i *discordgo.InteractionCreate) error {
-	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+	err := respond(s, i, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseModal,
-		Data: &discordgo.InteractionResponseData{
-			CustomID: "book_recommendation_" + i.Interaction.Member.User.ID,
-			Title:    "Book Recommendation",
-			Flags:    discordgo.MessageFlagsIsComponentsV2,
-			Components: []discordgo.MessageComponent{
-				discordgo.ActionsRow{
-					Components: []discordgo.MessageComponent{
-						discordgo.TextInput{
-							CustomID:  "book_title",
-							Label:     "Book Title",
-							Style:     discordgo.TextInputShort,
-							Required:  true,
-							MaxLength: 200,
-						},
-					},
-				},
-				discordgo.ActionsRow{
-					Components: []discordgo.MessageComponent{
-						discordgo.TextInput{
-							CustomID:  "book_author",
-							Label:     "Book Author",
-							Style:     discordgo.TextInputShort,
-							Required:  true,
-							MaxLength: 100,
-						},
-					},
-				},
-				discordgo.ActionsRow{
-					Components: []discordgo.MessageComponent{
-						discordgo.TextInput{
-							CustomID:  "book_goodreads_link",
-							Label:     "Goodreads Link (optional)",
-							Style:     discordgo.TextInputShort,
-							Required:  false,
-							MaxLength: 200,
-						},
-					},
-				},
-				discordgo.ActionsRow{
-					Components: []discordgo.MessageComponent{
-						discordgo.TextInput{
-							CustomID:  "book_description",
-							Label:     "Description (optional)",
-							Style:     discordgo.TextInputParagraph,
-							Required:  false,
-							MaxLength: 500,
-						},
-					},
-				},
-			},
-		},
+		Data: bookRecommendationForm.Modal("book_recommendation_"+interactionUserId(i), nil),
 	})
 	if err != nil {
 		return fmt.Errorf("Unable to send book recommendation modal: %v", err)
@@ -117,18 +98,80 @@ func HandleRecommendABook(s *discordgo.Session, i *discordgo.InteractionCreate)
 	return nil
 }
 
-func HandleRecommendABookModalResponse(s *discordgo.Session, i *discordgo.InteractionCreate) error {
-	t := loadClubTable()
+var bookRecommendationForm = Form{
+	Title: "Book Recommendation",
+	Fields: []FormField{
+		{
+			CustomID:  "book_title",
+			Label:     "Book Title",
+			Style:     discordgo.TextInputShort,
+			Required:  true,
+			MaxLength: 200,
+		},
+		{
+			CustomID:  "book_author",
+			Label:     "Book Author",
+			Style:     discordgo.TextInputShort,
+			Required:  true,
+			MaxLength: 100,
+		},
+		{
+			CustomID:   "book_goodreads_link",
+			Label:      "Goodreads Link (optional)",
+			Style:      discordgo.TextInputShort,
+			MaxLength:  200,
+			Validators: []Validator{ValidURL},
+		},
+		{
+			CustomID:  "book_description",
+			Label:     "Description (optional)",
+			Style:     discordgo.TextInputParagraph,
+			MaxLength: 500,
+		},
+		{
+			CustomID:  "book_genre",
+			Label:     "Genre (optional)",
+			Style:     discordgo.TextInputShort,
+			MaxLength: 50,
+		},
+	},
+}
 
-	d := i.ModalSubmitData()
-	title := d.Components[0].(*discordgo.ActionsRow).Components[0].(*discordgo.TextInput).Value         // book title
-	author := d.Components[1].(*discordgo.ActionsRow).Components[0].(*discordgo.TextInput).Value        // book author
-	goodreadsLink := d.Components[2].(*discordgo.ActionsRow).Components[0].(*discordgo.TextInput).Value // goodreads link (optional)
-	description := d.Components[3].(*discordgo.ActionsRow).Components[0].(*discordgo.TextInput).Value   // book description (optional)
+var editBookRecommendationForm = Form{
+	Title:  "Edit Book Recommendation",
+	Fields: bookRecommendationForm.Fields,
+}
 
-	fmt.Println("Received book recommendation:", title, author, goodreadsLink, description)
+func HandleRecommendABookModalResponse(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	var recommendation bookRecommendation
+	err := bookRecommendationForm.Decode(i.ModalSubmitData(), &recommendation)
+	if err != nil {
+		return respondFormError(s, i, err)
+	}
+	recommendation.RecommenderId = interactionUserId(i)
+	recommendation.RecommenderName = interactionUser(i).DisplayName()
+	recommendation.ChannelId = i.ChannelID
+
+	fmt.Println("Received book recommendation:", recommendation)
+
+	var book, existing models.BookEntry
+	found := false
+	err = updateClubTable(func(t *models.ClubTable) error {
+		existing, found = controllers.FindDuplicateBook(t.BookPool, recommendation.Title, recommendation.Author, recommendation.GoodreadsLink)
+		if found {
+			return nil
+		}
+		book, err = addRecommendedBook(t, recommendation)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if found {
+		return askAboutDuplicateBook(s, i, existing, recommendation)
+	}
 
-	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+	err = respond(s, i, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
 			Content: "Thank you for your recommendation! 📚",
@@ -139,188 +182,363 @@ func HandleRecommendABookModalResponse(s *discordgo.Session, i *discordgo.Intera
 		return fmt.Errorf("Unable to send book recommendation confirmation: %v", err)
 	}
 
-	err = controllers.AddBook(t.BookPool, title, author, goodreadsLink, description)
+	return postBookRecommendation(s, book, recommendation)
+}
+
+// bookRecommendation is a submitted recommendation that hasn't been added to the pool yet.
+type bookRecommendation struct {
+	Title           string `form:"book_title"`
+	Author          string `form:"book_author"`
+	GoodreadsLink   string `form:"book_goodreads_link"`
+	Description     string `form:"book_description"`
+	Genre           string `form:"book_genre"`
+	RecommenderId   string
+	RecommenderName string
+	ChannelId       string
+}
+
+func recommendationFromBook(book models.BookEntry) bookRecommendation {
+	return bookRecommendation{
+		Title:         book.Name,
+		Author:        book.Author,
+		GoodreadsLink: book.Link,
+		Description:   book.Description,
+		Genre:         book.Genre,
+		RecommenderId: book.RecommenderId,
+		ChannelId:     book.ChannelId,
+	}
+}
+
+// addRecommendedBook adds a recommended book to the pool.
+func addRecommendedBook(t *models.ClubTable, r bookRecommendation) (models.BookEntry, error) {
+	book, err := controllers.AddBook(&t.BookPool, r.Title, r.Author, r.GoodreadsLink, r.Description, r.Genre, r.RecommenderId)
+	if err != nil {
+		return book, fmt.Errorf("Unable to add book to the pool: %v", err)
+	}
+	return book, nil
+}
+
+// postBookRecommendation posts the voting embed for a book added by addRecommendedBook,
+// with any catalog metadata found for it. A book whose embed can't be posted is taken
+// back out of the pool, since members would have nothing to vote on.
+func postBookRecommendation(s *discordgo.Session, book models.BookEntry, r bookRecommendation) error {
+	metadata, isbn, enriched := lookupBookMetadata(book)
+	if enriched {
+		enriched_books := []models.BookEntry{book}
+		controllers.ApplyBookMetadata(enriched_books, book.Id, metadata, isbn)
+		book = enriched_books[0]
+	}
+
+	embed := bookRecommendationEmbed(book, fmt.Sprintf("%s recommended a new book! ", r.RecommenderName)+
+		"If you want to read this book for book club please press Vote below!")
+
+	message, err := s.ChannelMessageSendComplex(r.ChannelId, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: bookVoteButtons(book.Id),
+	})
 	if err != nil {
-		return fmt.Errorf("Unable to add book to the pool: %v", err)
+		withdraw_err := updateClubTable(func(t *models.ClubTable) error {
+			return controllers.WithdrawBook(t, book.Id)
+		})
+		if withdraw_err != nil {
+			log.Println("Unable to remove book without a recommendation embed:", withdraw_err)
+		}
+		return fmt.Errorf("Unable to send book recommendation embed: %v", err)
 	}
-	saveClubTable(t)
 
-	embed := discordgo.MessageEmbed{
-		Title: "New Book Recommendation Received! 📚",
-		Description: fmt.Sprintf("%s recommended a new book! ", i.Interaction.Member.User.DisplayName()) +
-			"If you want to read this book for book club please leave a ❤️ reaction below!",
-		Color: 0x00ff00, // Green color
+	return updateClubTable(func(t *models.ClubTable) error {
+		if enriched {
+			err := controllers.ApplyBookMetadata(t.BookPool, book.Id, metadata, isbn)
+			if err != nil {
+				return fmt.Errorf("Unable to store book metadata: %v", err)
+			}
+		}
+		err := controllers.SetBookMessage(t.BookPool, book.Id, message.ChannelID, message.ID)
+		if err != nil {
+			return fmt.Errorf("Unable to link book to its recommendation embed: %v", err)
+		}
+		return nil
+	})
+}
+
+// bookRecommendationEmbed builds the embed members vote on for a book.
+func bookRecommendationEmbed(book models.BookEntry, description string) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       "New Book Recommendation Received! 📚",
+		Description: description,
+		Color:       0x00ff00, // Green color
 		Fields: []*discordgo.MessageEmbedField{
 			{
 				Name:   "Title",
-				Value:  title,
+				Value:  book.Name,
 				Inline: true,
 			},
 			{
 				Name:   "Author",
-				Value:  author,
+				Value:  book.Author,
 				Inline: true,
 			},
 			{
 				Name:  "Goodreads Link",
-				Value: goodreadsLink,
+				Value: book.Link,
 			},
 			{
 				Name:  "Description",
-				Value: description,
+				Value: book.Description,
 			},
 		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: bookIdFooterPrefix + book.Id,
+		},
 	}
+	bookMetadataFields(embed, book)
+	return withVoteTally(embed, book.Votes)
+}
+
+func HandleSetSelectionStrategy(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	name := i.ApplicationCommandData().GetOption("strategy").StringValue()
 
-	message, err := s.ChannelMessageSendEmbed(i.ChannelID, &embed)
+	err := controllers.SetSelectionStrategy(&t.Settings, name)
 	if err != nil {
-		return fmt.Errorf("Unable to send book recommendation embed: %v", err)
+		return respondEphemeral(s, i, fmt.Sprintf("Unable to change the selection strategy: %v", err))
 	}
-	err = s.MessageReactionAdd(i.ChannelID, message.ID, "❤️")
+
+	strategy, err := controllers.GetSelectionStrategy(name)
 	if err != nil {
-		return fmt.Errorf("Unable to add reaction to book recommendation embed: %v", err)
+		return fmt.Errorf("Unable to load selection strategy: %v", err)
 	}
+	return respondEphemeral(s, i, fmt.Sprintf("Next books will be picked by **%s**: %s.", strategy.Name(), strategy.Description()))
+}
 
-	return nil
+func HandleSetVoteAging(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	data := i.ApplicationCommandData()
+
+	half_life_days := t.Settings.VoteHalfLifeDays
+	if option := data.GetOption("half-life-days"); option != nil {
+		half_life_days = int(option.IntValue())
+	}
+	window_months := t.Settings.VoteWindowMonths
+	if option := data.GetOption("window-months"); option != nil {
+		window_months = int(option.IntValue())
+	}
+	archive_after_months := t.Settings.ArchiveAfterMonths
+	if option := data.GetOption("archive-after-months"); option != nil {
+		archive_after_months = int(option.IntValue())
+	}
+
+	err := controllers.SetVoteAging(&t.Settings, half_life_days, window_months, archive_after_months)
+	if err != nil {
+		return respondEphemeral(s, i, fmt.Sprintf("Unable to change vote aging: %v", err))
+	}
+
+	return respondEphemeral(s, i, fmt.Sprintf(
+		"Vote aging updated. Half-life: %s. Votes count for: %s. Archive inactive books after: %s.",
+		describeLimit(half_life_days, "days"),
+		describeLimit(window_months, "months"),
+		describeLimit(archive_after_months, "months"),
+	))
 }
 
-func HandleRecommendACafe(s *discordgo.Session, i *discordgo.InteractionCreate) error {
-	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseModal,
+// describeLimit renders an optional limit where zero means turned off.
+func describeLimit(value int, unit string) string {
+	if value == 0 {
+		return "off"
+	}
+	return fmt.Sprintf("%d %s", value, unit)
+}
+
+func HandleReviveBook(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	book, err := t.GetBookByIdOrTitle(i.ApplicationCommandData().GetOption("book").StringValue())
+	if err != nil {
+		return respondEphemeral(s, i, fmt.Sprintf("Unable to revive book: %v", err))
+	}
+	book, err = controllers.ReviveBook(t.BookPool, book.Id, time.Now())
+	if err != nil {
+		return respondEphemeral(s, i, fmt.Sprintf("Unable to revive book: %v", err))
+	}
+
+	err = respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			CustomID: "cafe_recommendation_" + i.Interaction.Member.User.ID,
-			Title:    "Cafe Recommendation",
-			Flags:    discordgo.MessageFlagsIsComponentsV2,
-			Components: []discordgo.MessageComponent{
-				discordgo.ActionsRow{
-					Components: []discordgo.MessageComponent{
-						discordgo.TextInput{
-							CustomID:  "cafe_name",
-							Label:     "Cafe Name",
-							Style:     discordgo.TextInputShort,
-							Required:  true,
-							MaxLength: 200,
-						},
-					},
-				},
-				discordgo.ActionsRow{
-					Components: []discordgo.MessageComponent{
-						discordgo.TextInput{
-							CustomID:  "google_maps_link",
-							Label:     "Google Maps Link",
-							Style:     discordgo.TextInputShort,
-							Required:  true,
-							MaxLength: 200,
-						},
-					},
-				},
-			},
+			Content: fmt.Sprintf("📚 *%s* by %s is back in the running!", book.Name, book.Author),
 		},
 	})
 	if err != nil {
-		return fmt.Errorf("Unable to send cafe recommendation modal: %v", err)
+		return fmt.Errorf("Unable to send revive confirmation: %v", err)
 	}
 	return nil
 }
 
-func HandleRecommendACafeModalResponse(s *discordgo.Session, i *discordgo.InteractionCreate) error {
-	t := loadClubTable()
+func HandleSetRecommenderFairness(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	enabled := i.ApplicationCommandData().GetOption("enabled").BoolValue()
+	t.Settings.NoBackToBackRecommender = enabled
 
-	d := i.ModalSubmitData()
-	cafeName := d.Components[0].(*discordgo.ActionsRow).Components[0].(*discordgo.TextInput).Value       // cafe name
-	googleMapsLink := d.Components[1].(*discordgo.ActionsRow).Components[0].(*discordgo.TextInput).Value // google maps link
+	if enabled {
+		return respondEphemeral(s, i, "The same member's recommendations can no longer be picked back-to-back.")
+	}
+	return respondEphemeral(s, i, "Recommendations can be picked back-to-back again.")
+}
 
-	fmt.Println("Received cafe recommendation:", cafeName, googleMapsLink)
+func HandleBookInfo(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	book, err := t.GetBookByIdOrTitle(i.ApplicationCommandData().GetOption("book").StringValue())
+	if err != nil {
+		return respondEphemeral(s, i, fmt.Sprintf("Unable to find book: %v", err))
+	}
 
-	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+	err = respond(s, i, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Content: "Thank you for your recommendation! 📚",
-			Flags:   discordgo.MessageFlagsEphemeral,
+			Embeds:          []*discordgo.MessageEmbed{bookInfoEmbed(book)},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
 		},
 	})
 	if err != nil {
-		return fmt.Errorf("Unable to send cafe recommendation confirmation: %v", err)
-	}
-
-	err = controllers.AddCafe(t.CafePool, cafeName, googleMapsLink)
-	if err != nil {
-		return fmt.Errorf("Unable to add cafe to the pool: %v", err)
+		return fmt.Errorf("Unable to send book info: %v", err)
 	}
-	saveClubTable(t)
 	return nil
 }
 
-func HandleVotingReactions(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
-	log.Println("HandleVotingReactions")
-	// A. Ignore the bot's own reactions
-	if r.UserID == s.State.User.ID {
-		return
+func bookInfoEmbed(book models.BookEntry) *discordgo.MessageEmbed {
+	status := "Up for a vote"
+	switch {
+	case book.Read:
+		status = "Read"
+	case book.Queued:
+		status = "Up next"
+	case book.Archived:
+		status = "Archived"
 	}
-	// Skip non-heart reactions
-	if r.Emoji.Name != "❤️" {
-		return
+
+	recommender := "Unknown"
+	if book.RecommenderId != "" {
+		recommender = fmt.Sprintf("<@%s>", book.RecommenderId)
+	}
+
+	fields := []*discordgo.MessageEmbedField{
+		{Name: "Author", Value: book.Author, Inline: true},
+		{Name: "Recommended by", Value: recommender, Inline: true},
+		{Name: voteTallyFieldName, Value: fmt.Sprintf("%d", book.Votes), Inline: true},
+		{Name: "Status", Value: status, Inline: true},
 	}
+	if book.Genre != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Genre", Value: book.Genre, Inline: true})
+	}
+	if len(book.ContentWarnings) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Content Warnings", Value: strings.Join(book.ContentWarnings, ", ")})
+	}
+	if book.Link != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Goodreads Link", Value: book.Link})
+	}
+	if book.Description != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Description", Value: book.Description})
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:  fmt.Sprintf("📖 %s", book.Name),
+		Color:  0x00ff00, // Green color
+		Fields: fields,
+	}
+	bookMetadataFields(embed, book)
+	return embed
+}
+
+var cafeRecommendationForm = Form{
+	Title: "Cafe Recommendation",
+	Fields: []FormField{
+		{
+			CustomID:  "cafe_name",
+			Label:     "Cafe Name",
+			Style:     discordgo.TextInputShort,
+			Required:  true,
+			MaxLength: 200,
+		},
+		{
+			CustomID:   "google_maps_link",
+			Label:      "Google Maps Link",
+			Style:      discordgo.TextInputShort,
+			Required:   true,
+			MaxLength:  200,
+			Validators: []Validator{ValidURL},
+		},
+	},
+}
+
+type cafeRecommendation struct {
+	Name           string `form:"cafe_name"`
+	GoogleMapsLink string `form:"google_maps_link"`
+}
 
-	// B. Check if the message belongs to the Bot ("My Post")
-	// The event (r) only gives us the MessageID, not the Message author.
-	// We must fetch the message to see who wrote it.
-	// Try the local cache first (fastest)
-	msg, err := s.State.Message(r.ChannelID, r.MessageID)
+func HandleRecommendACafe(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	err := respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: cafeRecommendationForm.Modal("cafe_recommendation_"+interactionUserId(i), nil),
+	})
 	if err != nil {
-		// If not in cache (common for older messages), fetch from API
-		msg, err = s.ChannelMessage(r.ChannelID, r.MessageID)
-		if err != nil {
-			log.Println("Could not fetch message:", err)
-			return
-		}
-		// Add to cache for next time
-		s.State.MessageAdd(msg)
+		return fmt.Errorf("Unable to send cafe recommendation modal: %v", err)
 	}
+	return nil
+}
 
-	// Skip reaction on messages that aren't from the bot
-	if msg.Author.ID != s.State.User.ID {
-		return
+func HandleRecommendACafeModalResponse(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	var cafe cafeRecommendation
+	err := cafeRecommendationForm.Decode(i.ModalSubmitData(), &cafe)
+	if err != nil {
+		return respondFormError(s, i, err)
 	}
 
-	t := loadClubTable()
+	fmt.Println("Received cafe recommendation:", cafe.Name, cafe.GoogleMapsLink)
 
-	vote_count := 0
-	for _, react := range msg.Reactions {
-		if react.Emoji.Name == "❤️" {
-			vote_count += react.Count
-		}
+	err = respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: "Thank you for your recommendation! 📚",
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to send cafe recommendation confirmation: %v", err)
 	}
 
-	// Get the book title from the embed
-	if len(msg.Embeds) == 0 {
-		log.Println("No embeds found in the message")
-		return
-	}
-	for _, embed := range msg.Embeds {
-		for _, field := range embed.Fields {
-			if field.Name == "Title" {
-				bookName := field.Value
-				log.Println("Updating votes for book:", bookName, "to", vote_count)
-				err := controllers.UpdateVotes(t.BookPool, bookName, vote_count)
-				if err != nil {
-					log.Println("Error updating book vote count:", err)
-				}
-				saveClubTable(t)
-				return
-			}
-		}
+	err = controllers.AddCafe(&t.CafePool, cafe.Name, cafe.GoogleMapsLink)
+	if err != nil {
+		return fmt.Errorf("Unable to add cafe to the pool: %v", err)
 	}
+	return nil
 }
 
-func loadClubTable() models.ClubTable {
-	file, _ := os.ReadFile("club_table.json")
+const clubTableFile = "club_table.json"
+
+// loadClubTable reads the club table. It is empty until the first save.
+func loadClubTable() (models.ClubTable, error) {
 	var data models.ClubTable
-	json.Unmarshal(file, &data)
-	return data
+	file, err := os.ReadFile(clubTableFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return data, nil
+	}
+	if err != nil {
+		return data, fmt.Errorf("Unable to read club table: %v", err)
+	}
+	err = json.Unmarshal(file, &data)
+	if err != nil {
+		return data, fmt.Errorf("Unable to parse club table: %v", err)
+	}
+	return data, nil
 }
 
-func saveClubTable(t models.ClubTable) {
-	data, _ := json.MarshalIndent(t, "", "  ")
-	os.WriteFile("club_table.json", data, 0644)
+// saveClubTable writes the club table to a temporary file and renames it into place,
+// so a crash part way through can't leave a truncated table behind.
+func saveClubTable(t models.ClubTable) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Unable to encode club table: %v", err)
+	}
+	temp := clubTableFile + ".tmp"
+	err = os.WriteFile(temp, data, 0644)
+	if err != nil {
+		return fmt.Errorf("Unable to write club table: %v", err)
+	}
+	err = os.Rename(temp, clubTableFile)
+	if err != nil {
+		return fmt.Errorf("Unable to replace club table: %v", err)
+	}
+	return nil
 }
@@ -0,0 +1,148 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/controllers"
+	"bookclubbot.com/main/models"
+	"bookclubbot.com/main/scheduler"
+)
+
+// RunScheduledJobs runs the bot's recurring jobs until the context is cancelled.
+func RunScheduledJobs(ctx context.Context, s *discordgo.Session) {
+	jobs := scheduler.New(scheduler.RealClock, clubTableJobStore{})
+	jobs.Add(weeklyAnnouncementJob(s))
+	jobs.Add(meetupRemindersJob(s))
+	jobs.Add(meetupEventsJob(s))
+	jobs.Run(ctx)
+}
+
+// clubTableJobStore keeps the scheduler's state in the club table.
+type clubTableJobStore struct{}
+
+func (clubTableJobStore) LastRun(name string) time.Time {
+	t, err := readClubTable()
+	if err != nil {
+		log.Println("Unable to look up when a job last ran:", err)
+		return time.Time{}
+	}
+	for _, run := range t.JobRuns {
+		if run.Name == name {
+			return run.LastRun
+		}
+	}
+	return time.Time{}
+}
+
+func (clubTableJobStore) SetLastRun(name string, at time.Time) error {
+	return updateClubTable(func(t *models.ClubTable) error {
+		for n := range t.JobRuns {
+			if t.JobRuns[n].Name == name {
+				t.JobRuns[n].LastRun = at
+				return nil
+			}
+		}
+		t.JobRuns = append(t.JobRuns, models.JobRunEntry{Name: name, LastRun: at})
+		return nil
+	})
+}
+
+// clubSchedule reads a job's spec from the club settings, in the club's time zone.
+func clubSchedule(spec func(settings models.ClubSettings) string) func() (scheduler.Spec, bool) {
+	return func() (scheduler.Spec, bool) {
+		t, err := readClubTable()
+		if err != nil {
+			log.Println("Unable to schedule job:", err)
+			return scheduler.Spec{}, false
+		}
+		if spec(t.Settings) == "" {
+			return scheduler.Spec{}, false
+		}
+		location, err := controllers.ClubLocation(t.Settings)
+		if err != nil {
+			log.Println("Unable to schedule job:", err)
+			return scheduler.Spec{}, false
+		}
+		parsed, err := scheduler.ParseSpec(spec(t.Settings), location)
+		if err != nil {
+			log.Println("Unable to schedule job:", err)
+			return scheduler.Spec{}, false
+		}
+		return parsed, true
+	}
+}
+
+func weeklyAnnouncementJob(s *discordgo.Session) scheduler.Job {
+	return scheduler.Job{
+		Name: "weekly_announcement",
+		Schedule: clubSchedule(func(settings models.ClubSettings) string {
+			if settings.AnnouncementChannelId == "" {
+				return ""
+			}
+			return settings.AnnouncementSchedule
+		}),
+		// An announcement a few hours late is still useful, one a day late isn't.
+		CatchUp: 6 * time.Hour,
+		Run: func(due time.Time) error {
+			var announcement controllers.Announcement
+			var channelId string
+			err := updateClubTable(func(t *models.ClubTable) error {
+				err := planSchedule(t)
+				if err != nil {
+					return err
+				}
+				announcement, err = controllers.ComposeAnnouncement(t, due)
+				channelId = t.Settings.AnnouncementChannelId
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			// Posted after the schedule is saved, so the club table isn't locked while Discord is called.
+			return postAnnouncement(s, channelId, announcement.Meetup.Id, []*discordgo.MessageEmbed{announcementEmbed(announcement)})
+		},
+	}
+}
+
+var weekdayChoices = func() []*discordgo.ApplicationCommandOptionChoice {
+	choices := []*discordgo.ApplicationCommandOptionChoice{{Name: "Never (turn off)", Value: -1}}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: day.String(), Value: int(day)})
+	}
+	return choices
+}()
+
+func HandleScheduleAnnouncements(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	data := i.ApplicationCommandData()
+	weekday := int(data.GetOption("day").IntValue())
+	clock := "18:00"
+	if option := data.GetOption("time"); option != nil {
+		clock = option.StringValue()
+	}
+	timezone := ""
+	if option := data.GetOption("timezone"); option != nil {
+		timezone = option.StringValue()
+	}
+
+	err := controllers.SetWeeklyAnnouncement(&t.Settings, weekday, clock, timezone)
+	if err != nil {
+		return respondEphemeral(s, i, fmt.Sprintf("Unable to schedule announcements: %v", err))
+	}
+	if weekday < 0 {
+		return respondEphemeral(s, i, "Weekly announcements are turned off.")
+	}
+	reply := fmt.Sprintf("The weekly announcement will be posted every %s at %s", time.Weekday(weekday), clock)
+	if t.Settings.TimeZone != "" {
+		reply += fmt.Sprintf(" (%s)", t.Settings.TimeZone)
+	}
+	reply += "."
+	if t.Settings.AnnouncementChannelId == "" {
+		reply += " Choose where with /set-announcement-channel first."
+	}
+	return respondEphemeral(s, i, reply)
+}
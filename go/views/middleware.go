@@ -0,0 +1,184 @@
+package views
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/models"
+)
+
+// HandlerFunc handles a slash command, a modal submission or a component interaction.
+type HandlerFunc func(s *discordgo.Session, i *discordgo.InteractionCreate) error
+
+// Middleware wraps a handler with behaviour that runs before or after it.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// chain wraps a handler in middleware. The first middleware listed runs first.
+func chain(handler HandlerFunc, middleware ...Middleware) HandlerFunc {
+	for n := len(middleware) - 1; n >= 0; n-- {
+		handler = middleware[n](handler)
+	}
+	return handler
+}
+
+// standardMiddleware wraps every handler, ahead of the middleware it declares itself.
+func standardMiddleware(name string) []Middleware {
+	return []Middleware{withLogging(name), withRecovery, withTiming(name)}
+}
+
+func withLogging(name string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+			log.Println("Handling", name, "for", interactionUserId(i))
+			return next(s, i)
+		}
+	}
+}
+
+// withRecovery turns a panicking handler into an error, so the user still hears back.
+func withRecovery(next HandlerFunc) HandlerFunc {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("Handler panicked: %v\n%s", r, debug.Stack())
+			}
+		}()
+		return next(s, i)
+	}
+}
+
+// Handlers slower than this are logged so they can be looked into.
+var slowHandlerThreshold = time.Second
+
+func withTiming(name string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+			start := time.Now()
+			err := next(s, i)
+			if elapsed := time.Since(start); elapsed > slowHandlerThreshold {
+				log.Printf("Slow handler for %s took %v", name, elapsed.Round(time.Millisecond))
+			}
+			return err
+		}
+	}
+}
+
+// withRateLimit lets each member through at most limit times per period.
+func withRateLimit(limit int, period time.Duration) Middleware {
+	limiter := &rateLimiter{limit: limit, period: period, now: time.Now, recent: map[string][]time.Time{}}
+	return func(next HandlerFunc) HandlerFunc {
+		return func(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+			wait := limiter.allow(interactionUserId(i))
+			if wait > 0 {
+				return respondEphemeral(s, i, fmt.Sprintf("You're doing that too often. Try again in %v.", wait.Round(time.Second)))
+			}
+			return next(s, i)
+		}
+	}
+}
+
+type rateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	period time.Duration
+	now    func() time.Time
+	recent map[string][]time.Time
+}
+
+// allow records an attempt by a member, or returns how long they have to wait.
+func (l *rateLimiter) allow(userId string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	attempts := []time.Time{}
+	for _, attempt := range l.recent[userId] {
+		if now.Sub(attempt) < l.period {
+			attempts = append(attempts, attempt)
+		}
+	}
+	if len(attempts) >= l.limit {
+		l.recent[userId] = attempts
+		return attempts[0].Add(l.period).Sub(now)
+	}
+	l.recent[userId] = append(attempts, now)
+	return 0
+}
+
+// TableHandlerFunc is a handler that reads or changes the club table.
+type TableHandlerFunc func(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error
+
+// Every read and write of the club table takes a turn with this lock, so one change
+// can't overwrite another that was saved after it loaded the table.
+var clubTableMutex sync.Mutex
+
+// withClubTable loads the club table for a handler and saves it if the handler succeeds.
+// The handler's responses are sent once the table is saved and unlocked, so handlers
+// that call Discord or other services for more than a response use updateClubTable.
+func withClubTable(handler TableHandlerFunc) HandlerFunc {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+		holdResponses(i)
+		// Responses are dropped if the handler fails or panics, so only its error is reported.
+		defer releaseResponses(s, i, false)
+		err := updateClubTable(func(t *models.ClubTable) error {
+			return handler(s, i, t)
+		})
+		if err != nil {
+			return err
+		}
+		return releaseResponses(s, i, true)
+	}
+}
+
+// readingClubTable loads the club table for a handler that only reads it.
+func readingClubTable(handler TableHandlerFunc) HandlerFunc {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+		t, err := readClubTable()
+		if err != nil {
+			return err
+		}
+		return handler(s, i, &t)
+	}
+}
+
+// updateClubTable loads the club table, changes it and saves it if the change succeeds.
+// Events other than interactions, such as reactions and timers, use it directly.
+func updateClubTable(change func(t *models.ClubTable) error) error {
+	clubTableMutex.Lock()
+	defer clubTableMutex.Unlock()
+	t, err := loadClubTable()
+	if err != nil {
+		return err
+	}
+	err = change(&t)
+	if err != nil {
+		return err
+	}
+	return saveClubTable(t)
+}
+
+// readClubTable loads the club table without saving it.
+func readClubTable() (models.ClubTable, error) {
+	clubTableMutex.Lock()
+	defer clubTableMutex.Unlock()
+	return loadClubTable()
+}
+
+// interactionUser is the member or, in DMs, the user behind an interaction.
+func interactionUser(i *discordgo.InteractionCreate) *discordgo.User {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User
+	}
+	if i.User != nil {
+		return i.User
+	}
+	return &discordgo.User{}
+}
+
+func interactionUserId(i *discordgo.InteractionCreate) string {
+	return interactionUser(i).ID
+}
@@ -0,0 +1,159 @@
+package views
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/models"
+)
+
+func TestChain_RunsMiddlewareInOrder(t *testing.T) {
+	calls := []string{}
+	record := func(name string) Middleware {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+				calls = append(calls, name)
+				return next(s, i)
+			}
+		}
+	}
+	handler := chain(func(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+		calls = append(calls, "handler")
+		return nil
+	}, record("first"), record("second"))
+
+	handler(nil, newCommandInteraction())
+	if strings.Join(calls, ",") != "first,second,handler" {
+		t.Errorf("Unexpected call order %v", calls)
+	}
+}
+
+func TestWithRecovery_ReturnsPanicsAsErrors(t *testing.T) {
+	handler := withRecovery(func(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+		var book *struct{ Name string }
+		_ = book.Name
+		return nil
+	})
+
+	err := handler(nil, newCommandInteraction())
+	if err == nil || !strings.Contains(err.Error(), "panicked") {
+		t.Errorf("Expected the panic to be returned as an error, got %v", err)
+	}
+}
+
+func TestRateLimiter_AllowsLimitPerPeriod(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	limiter := &rateLimiter{limit: 2, period: time.Minute, now: func() time.Time { return now }, recent: map[string][]time.Time{}}
+
+	if limiter.allow("alice") != 0 || limiter.allow("alice") != 0 {
+		t.Fatalf("Expected the first two attempts to be allowed")
+	}
+	now = now.Add(20 * time.Second)
+	if wait := limiter.allow("alice"); wait != 40*time.Second {
+		t.Errorf("Expected to wait 40s, got %v", wait)
+	}
+	if limiter.allow("bob") != 0 {
+		t.Errorf("Expected other members not to be limited")
+	}
+	now = now.Add(40 * time.Second)
+	if wait := limiter.allow("alice"); wait != 0 {
+		t.Errorf("Expected the limit to reset after the period, got %v", wait)
+	}
+}
+
+func TestUpdateClubTable_KeepsConcurrentChanges(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := updateClubTable(func(table *models.ClubTable) error {
+				table.CafePool = append(table.CafePool, models.CafeEntry{Name: "Cafe"})
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	table, err := readClubTable()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(table.CafePool) != 20 {
+		t.Errorf("Expected 20 cafes, got %d", len(table.CafePool))
+	}
+}
+
+func TestUpdateClubTable_LeavesUnreadableTableAlone(t *testing.T) {
+	t.Chdir(t.TempDir())
+	os.WriteFile(clubTableFile, []byte("{not json"), 0644)
+
+	err := updateClubTable(func(table *models.ClubTable) error {
+		return nil
+	})
+	if err == nil {
+		t.Fatal("Expected an error for an unreadable club table")
+	}
+	data, _ := os.ReadFile(clubTableFile)
+	if string(data) != "{not json" {
+		t.Errorf("Unreadable club table was overwritten with %q", data)
+	}
+}
+
+func TestWithClubTable_RespondsAfterTheTableIsReleased(t *testing.T) {
+	t.Chdir(t.TempDir())
+	s, transport := newRecordingSession()
+	transport.respond = func(request recordedRequest) (int, string) {
+		// The club table must be free by the time Discord is called.
+		if !clubTableMutex.TryLock() {
+			t.Errorf("Discord was called while the club table was locked: %s", request.Path)
+		} else {
+			clubTableMutex.Unlock()
+		}
+		return http.StatusOK, `{"id": "1"}`
+	}
+
+	handleInteraction(s, newCommandInteraction(), "command test", withClubTable(func(s *discordgo.Session, i *discordgo.InteractionCreate, table *models.ClubTable) error {
+		table.CafePool = append(table.CafePool, models.CafeEntry{Name: "Cafe"})
+		return respondEphemeral(s, i, "Added!")
+	}))
+
+	requests := transport.recorded()
+	if len(requests) != 1 || requests[0].Body["data"].(map[string]any)["content"] != "Added!" {
+		t.Fatalf("Expected the handler's response, got %+v", requests)
+	}
+	table, _ := readClubTable()
+	if len(table.CafePool) != 1 {
+		t.Errorf("Expected the change to be saved")
+	}
+}
+
+func TestWithClubTable_DropsResponsesWhenTheHandlerFails(t *testing.T) {
+	t.Chdir(t.TempDir())
+	s, transport := newRecordingSession()
+
+	handleInteraction(s, newCommandInteraction(), "command test", withClubTable(func(s *discordgo.Session, i *discordgo.InteractionCreate, table *models.ClubTable) error {
+		respondEphemeral(s, i, "Added!")
+		return errors.New("the club table is on fire")
+	}))
+
+	requests := transport.recorded()
+	if len(requests) != 1 {
+		t.Fatalf("Expected a single interaction response, got %+v", requests)
+	}
+	if content := requests[0].Body["data"].(map[string]any)["content"].(string); !strings.Contains(content, "reference `") {
+		t.Errorf("Expected only the error to be reported, got %q", content)
+	}
+}
@@ -0,0 +1,139 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/controllers"
+	"bookclubbot.com/main/models"
+)
+
+// Organizers are members who can manage the server. They always have every capability.
+var organizerPermissions int64 = discordgo.PermissionManageGuild
+
+func isOrganizer(i *discordgo.InteractionCreate) bool {
+	return i.Interaction.Member != nil && i.Interaction.Member.Permissions&organizerPermissions != 0
+}
+
+// memberCan reports whether the member behind an interaction has a capability in its guild.
+func memberCan(i *discordgo.InteractionCreate, t *models.ClubTable, capability models.Capability) bool {
+	roleIds := []string{}
+	if i.Member != nil {
+		roleIds = i.Member.Roles
+	}
+	return controllers.MemberCan(t, i.GuildID, roleIds, isOrganizer(i), capability)
+}
+
+// Capabilities come from guild roles, so commands that need one only work in the server.
+var guildOnlyContexts = []discordgo.InteractionContextType{discordgo.InteractionContextGuild}
+
+// requireCapability only lets members with a capability through. Outside a guild there
+// are no roles to check, so nobody gets through.
+func requireCapability(capability models.Capability) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+			if i.Member == nil {
+				return respondEphemeral(s, i, "That only works in the server.")
+			}
+			t, err := readClubTable()
+			if err != nil {
+				return err
+			}
+			if !memberCan(i, &t, capability) {
+				return respondEphemeral(s, i, fmt.Sprintf("You need the %s capability to do that. Ask an organizer if you think you should have it.", capabilityName(capability)))
+			}
+			return next(s, i)
+		}
+	}
+}
+
+func capabilityName(capability models.Capability) string {
+	return strings.ReplaceAll(string(capability), "_", " ")
+}
+
+func permissionOptions() []*discordgo.ApplicationCommandOption {
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, capability := range controllers.Capabilities() {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  capabilityName(capability),
+			Value: string(capability),
+		})
+	}
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionRole,
+			Name:        "role",
+			Description: "The role to change (@everyone changes what all members can do)",
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "capability",
+			Description: "What members with the role can do",
+			Required:    true,
+			Choices:     choices,
+		},
+	}
+}
+
+func HandlePermissions(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	if i.GuildID == "" {
+		return respondEphemeral(s, i, "Permissions can only be changed in a server.")
+	}
+	subcommand := i.ApplicationCommandData().Options[0]
+	if subcommand.Name == "list" {
+		return respondEphemeral(s, i, describeRoleGrants(t, i.GuildID))
+	}
+
+	// Only the role's ID is needed, so it isn't looked up.
+	role := subcommand.GetOption("role").RoleValue(nil, "")
+	capability, err := controllers.ParseCapability(subcommand.GetOption("capability").StringValue())
+	if err != nil {
+		return respondEphemeral(s, i, err.Error())
+	}
+	if subcommand.Name == "grant" {
+		err = controllers.GrantCapability(t, i.GuildID, role.ID, capability)
+	} else {
+		err = controllers.RevokeCapability(t, i.GuildID, role.ID, capability)
+	}
+	if err != nil {
+		return respondEphemeral(s, i, fmt.Sprintf("Unable to change permissions: %v", err))
+	}
+	return respondEphemeral(s, i, fmt.Sprintf("%s can now: %s.", roleMention(i.GuildID, role.ID), describeCapabilities(controllers.RoleCapabilities(t, i.GuildID, role.ID))))
+}
+
+func describeRoleGrants(t *models.ClubTable, guildId string) string {
+	lines := []string{
+		"**🔑 Permissions**",
+		fmt.Sprintf("%s: %s", roleMention(guildId, guildId), describeCapabilities(controllers.RoleCapabilities(t, guildId, guildId))),
+	}
+	for _, grant := range t.RoleGrants {
+		if grant.GuildId != guildId || grant.RoleId == guildId {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", roleMention(guildId, grant.RoleId), describeCapabilities(grant.Capabilities)))
+	}
+	lines = append(lines, "Members who can manage the server can always do everything.")
+	return strings.Join(lines, "\n")
+}
+
+// roleMention names a role without pinging it in an ephemeral reply.
+func roleMention(guildId string, roleId string) string {
+	if roleId == guildId {
+		return "Everyone"
+	}
+	return fmt.Sprintf("<@&%s>", roleId)
+}
+
+func describeCapabilities(capabilities []models.Capability) string {
+	if len(capabilities) == 0 {
+		return "nothing"
+	}
+	names := []string{}
+	for _, capability := range capabilities {
+		names = append(names, capabilityName(capability))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,68 @@
+package views
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/models"
+)
+
+func TestMemberCan(t *testing.T) {
+	table := models.ClubTable{RoleGrants: []models.RoleGrantEntry{
+		{GuildId: "guild", RoleId: "planners", Capabilities: []models.Capability{models.CapabilityPlanSchedule}},
+	}}
+	i := newCommandInteraction()
+	i.GuildID = "guild"
+	i.Member = &discordgo.Member{User: &discordgo.User{ID: "member"}}
+
+	if !memberCan(i, &table, models.CapabilityVote) || memberCan(i, &table, models.CapabilityPlanSchedule) {
+		t.Errorf("Expected members without roles to have the default capabilities")
+	}
+	i.Member.Roles = []string{"planners"}
+	if !memberCan(i, &table, models.CapabilityPlanSchedule) {
+		t.Errorf("Expected the planners role to grant plan schedule")
+	}
+	i.Member.Roles = nil
+	i.Member.Permissions = discordgo.PermissionManageGuild
+	if !memberCan(i, &table, models.CapabilityAdmin) {
+		t.Errorf("Expected organizers to have every capability")
+	}
+}
+
+func TestGetSlashCommands_HidesAdminCommands(t *testing.T) {
+	for _, cmd := range getSlashCommands() {
+		hidden := cmd.DefaultMemberPermissions != nil && *cmd.DefaultMemberPermissions == organizerPermissions
+		if hidden != (cmd.Capability == models.CapabilityAdmin) {
+			t.Errorf("Command %s with capability %q has default permissions %v", cmd.Name, cmd.Capability, cmd.DefaultMemberPermissions)
+		}
+	}
+}
+
+func TestRequireCapability_RejectsDMs(t *testing.T) {
+	t.Chdir(t.TempDir())
+	s, transport := newRecordingSession()
+	i := newCommandInteraction()
+	i.User = &discordgo.User{ID: "member"}
+
+	called := false
+	handler := requireCapability(models.CapabilityVote)(func(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+		called = true
+		return nil
+	})
+	if err := handler(s, i); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if called || len(transport.recorded()) != 1 {
+		t.Errorf("Expected the DM to be turned away")
+	}
+}
+
+func TestGetSlashCommands_KeepsCapabilityCommandsInGuilds(t *testing.T) {
+	for _, cmd := range getSlashCommands() {
+		guildOnly := cmd.Contexts != nil && len(*cmd.Contexts) == 1 && (*cmd.Contexts)[0] == discordgo.InteractionContextGuild
+		if guildOnly != (cmd.Capability != "") {
+			t.Errorf("Command %s with capability %q has contexts %v", cmd.Name, cmd.Capability, cmd.Contexts)
+		}
+	}
+}
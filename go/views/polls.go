@@ -0,0 +1,311 @@
+package views
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/controllers"
+	"bookclubbot.com/main/models"
+)
+
+// Poll ballots are made of one select menu per rank. The custom ID is the prefix,
+// the rank (0 is first choice) and the poll ID, e.g. "book_poll_rank_0_<poll id>".
+const bookPollRankPrefix = "book_poll_rank_"
+
+const (
+	defaultPollFinalists = 5
+	defaultPollHours     = 48
+	// A message holds at most 5 action rows, one per ranked select menu.
+	maxPollFinalists = 5
+	// Two weeks is plenty, and keeps the deadline far from overflowing a time.Duration.
+	maxPollHours = 24 * 14
+)
+
+// Option bounds are passed to discordgo by pointer.
+var (
+	minPollFinalists = 2.0
+	minPollHours     = 1.0
+)
+
+func HandleStartBookPoll(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	data := i.ApplicationCommandData()
+	finalist_count := defaultPollFinalists
+	if option := data.GetOption("finalists"); option != nil {
+		finalist_count = int(option.IntValue())
+	}
+	hours := defaultPollHours
+	if option := data.GetOption("hours"); option != nil {
+		hours = int(option.IntValue())
+	}
+	if hours < int(minPollHours) || hours > maxPollHours {
+		return respondEphemeral(s, i, fmt.Sprintf("Polls can stay open for %d to %d hours.", int(minPollHours), maxPollHours))
+	}
+
+	var poll models.PollEntry
+	var finalists []models.BookEntry
+	err := updateClubTable(func(t *models.ClubTable) error {
+		now := time.Now()
+		controllers.ApplyVetoes(t, now)
+		finalists = controllers.TopUnreadBooks(t.BookPool, finalist_count)
+		var err error
+		poll, err = controllers.StartPoll(t, finalists, now.Add(time.Duration(hours)*time.Hour))
+		if err != nil {
+			return fmt.Errorf("Unable to start book poll: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	message, err := s.ChannelMessageSendComplex(i.ChannelID, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{bookPollEmbed(poll, finalists)},
+		Components: bookPollBallot(poll, finalists),
+	})
+	if err != nil {
+		discard_err := updateClubTable(func(t *models.ClubTable) error {
+			controllers.DiscardPoll(t, poll.Id)
+			return nil
+		})
+		if discard_err != nil {
+			log.Println("Unable to discard unposted book poll:", discard_err)
+		}
+		return fmt.Errorf("Unable to send book poll: %v", err)
+	}
+	err = updateClubTable(func(t *models.ClubTable) error {
+		return controllers.SetPollMessage(t, poll.Id, message.ChannelID, message.ID)
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to link poll to its message: %v", err)
+	}
+
+	poll.ChannelId = message.ChannelID
+	poll.MessageId = message.ID
+	scheduleBookPollClose(s, poll)
+
+	return respondEphemeral(s, i, "The book poll has started! 🗳️")
+}
+
+func bookPollEmbed(poll models.PollEntry, finalists []models.BookEntry) *discordgo.MessageEmbed {
+	lines := []string{}
+	for n, book := range finalists {
+		lines = append(lines, fmt.Sprintf("%d. *%s* by %s", n+1, book.Name, book.Author))
+	}
+	return &discordgo.MessageEmbed{
+		Title: "📊 Which book should we read next?",
+		Description: "Rank the finalists below, starting with your favorite. " +
+			"You can rank as many or as few as you like.\n\n" +
+			strings.Join(lines, "\n") +
+			fmt.Sprintf("\n\nVoting closes <t:%d:R>.", poll.Deadline.Unix()),
+		Color: 0x00ff00, // Green color
+	}
+}
+
+func bookPollBallot(poll models.PollEntry, finalists []models.BookEntry) []discordgo.MessageComponent {
+	options := []discordgo.SelectMenuOption{}
+	for _, book := range finalists {
+		options = append(options, discordgo.SelectMenuOption{
+			Label:       truncate(book.Name, 100),
+			Description: truncate(book.Author, 100),
+			Value:       book.Id,
+		})
+	}
+	rows := []discordgo.MessageComponent{}
+	for rank := range min(len(finalists), maxPollFinalists) {
+		rows = append(rows, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					MenuType:    discordgo.StringSelectMenu,
+					CustomID:    fmt.Sprintf("%s%d_%s", bookPollRankPrefix, rank, poll.Id),
+					Placeholder: ordinal(rank+1) + " choice",
+					Options:     options,
+				},
+			},
+		})
+	}
+	return rows
+}
+
+func HandleBookPollRank(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	d := i.MessageComponentData()
+	rank_and_poll := strings.SplitN(strings.TrimPrefix(d.CustomID, bookPollRankPrefix), "_", 2)
+	if len(rank_and_poll) != 2 {
+		return fmt.Errorf("Malformed poll custom ID %s", d.CustomID)
+	}
+	rank, err := strconv.Atoi(rank_and_poll[0])
+	if err != nil {
+		return fmt.Errorf("Malformed poll rank in %s: %v", d.CustomID, err)
+	}
+	if len(d.Values) == 0 {
+		return fmt.Errorf("No book selected in %s", d.CustomID)
+	}
+
+	ballot, err := controllers.CastRankedVote(t, rank_and_poll[1], interactionUserId(i), rank, d.Values[0], time.Now())
+	if err != nil {
+		return respondEphemeral(s, i, fmt.Sprintf("Your vote wasn't counted: %v", err))
+	}
+
+	lines := []string{"**Your ballot**"}
+	for n, bookId := range ballot.Rankings {
+		if bookId == "" {
+			continue
+		}
+		book, err := t.GetBookById(bookId)
+		if err != nil {
+			return fmt.Errorf("Unable to find ranked book: %v", err)
+		}
+		lines = append(lines, fmt.Sprintf("%s: *%s*", ordinal(n+1), book.Name))
+	}
+	return respondEphemeral(s, i, strings.Join(lines, "\n"))
+}
+
+// ResumeBookPolls schedules the closing of polls that were open when the bot last stopped.
+func ResumeBookPolls(s *discordgo.Session) {
+	t, err := readClubTable()
+	if err != nil {
+		log.Println("Error loading book polls:", err)
+		return
+	}
+	for _, poll := range t.Polls {
+		if !poll.Closed {
+			scheduleBookPollClose(s, poll)
+		}
+	}
+}
+
+func scheduleBookPollClose(s *discordgo.Session, poll models.PollEntry) {
+	time.AfterFunc(time.Until(poll.Deadline), func() {
+		err := closeBookPoll(s, poll.Id)
+		if err != nil {
+			log.Println("Error closing book poll:", err)
+		}
+	})
+}
+
+func closeBookPoll(s *discordgo.Session, pollId string) error {
+	var t models.ClubTable
+	var poll models.PollEntry
+	var winner string
+	var rounds []controllers.RunoffRound
+	var tally_err error
+	err := updateClubTable(func(table *models.ClubTable) error {
+		var err error
+		poll, err = table.GetPollById(pollId)
+		if err != nil {
+			return err
+		}
+		winner, rounds, tally_err = controllers.ClosePoll(table, pollId)
+		if poll.Closed {
+			return tally_err
+		}
+		t = *table
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Lock the ballot now that voting is over.
+	_, err = s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		Channel:    poll.ChannelId,
+		ID:         poll.MessageId,
+		Components: &[]discordgo.MessageComponent{},
+	})
+	if err != nil {
+		log.Println("Unable to remove ballot from closed poll:", err)
+	}
+
+	results := "**📊 Book Poll Results**\n\nNo ballots were cast, so the next book will be picked as usual."
+	if tally_err == nil {
+		results, err = renderRunoffResults(&t, winner, rounds)
+		if err != nil {
+			return fmt.Errorf("Unable to render poll results: %v", err)
+		}
+	}
+	_, err = s.ChannelMessageSend(poll.ChannelId, results)
+	if err != nil {
+		return fmt.Errorf("Unable to send poll results: %v", err)
+	}
+	return nil
+}
+
+func renderRunoffResults(t *models.ClubTable, winner string, rounds []controllers.RunoffRound) (string, error) {
+	book_name := func(id string) (string, error) {
+		book, err := t.GetBookById(id)
+		if err != nil {
+			return "", err
+		}
+		return book.Name, nil
+	}
+
+	lines := []string{"**📊 Book Poll Results**", ""}
+	for n, round := range rounds {
+		tallies := []string{}
+		for _, id := range sortedByTally(round.Tallies) {
+			name, err := book_name(id)
+			if err != nil {
+				return "", err
+			}
+			tallies = append(tallies, fmt.Sprintf("*%s* %d", name, round.Tallies[id]))
+		}
+		line := fmt.Sprintf("**Round %d**: %s", n+1, strings.Join(tallies, " · "))
+		for _, id := range round.Eliminated {
+			name, err := book_name(id)
+			if err != nil {
+				return "", err
+			}
+			line += fmt.Sprintf(" — *%s* eliminated", name)
+		}
+		lines = append(lines, line)
+	}
+
+	name, err := book_name(winner)
+	if err != nil {
+		return "", err
+	}
+	lines = append(lines, "", fmt.Sprintf("🏆 *%s* wins and will be scheduled next!", name))
+	return strings.Join(lines, "\n"), nil
+}
+
+func sortedByTally(tallies map[string]int) []string {
+	ids := []string{}
+	for id := range tallies {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if tallies[ids[i]] != tallies[ids[j]] {
+			return tallies[ids[i]] > tallies[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+// truncate shortens a string to fit Discord's component length limits.
+func truncate(s string, max_length int) string {
+	runes := []rune(s)
+	if len(runes) <= max_length {
+		return s
+	}
+	return string(runes[:max_length-1]) + "…"
+}
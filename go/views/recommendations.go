@@ -0,0 +1,177 @@
+package views
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/controllers"
+	"bookclubbot.com/main/models"
+)
+
+const bookEditModalPrefix = "book_edit_"
+
+// canManageBook reports whether the member recommended the book or is an organizer.
+func canManageBook(i *discordgo.InteractionCreate, book models.BookEntry) bool {
+	return book.RecommenderId == interactionUserId(i) || isOrganizer(i)
+}
+
+// describeRecommendation names a book for a confirmation, worded by who made the change.
+func describeRecommendation(i *discordgo.InteractionCreate, book models.BookEntry) string {
+	if book.RecommenderId == interactionUserId(i) {
+		return fmt.Sprintf("Your recommendation of *%s*", book.Name)
+	}
+	if book.RecommenderId == "" {
+		return fmt.Sprintf("*%s*", book.Name)
+	}
+	return fmt.Sprintf("*%s* (recommended by <@%s>)", book.Name, book.RecommenderId)
+}
+
+func HandleEditRecommendation(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	book, err := t.GetBookByIdOrTitle(i.ApplicationCommandData().GetOption("book").StringValue())
+	if err != nil {
+		return respondEphemeral(s, i, fmt.Sprintf("Unable to edit recommendation: %v", err))
+	}
+	if !canManageBook(i, book) {
+		return respondEphemeral(s, i, "Only the member who recommended this book or an organizer can edit it.")
+	}
+
+	err = respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: editBookRecommendationForm.Modal(bookEditModalPrefix+book.Id, recommendationFromBook(book)),
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to send edit recommendation modal: %v", err)
+	}
+	return nil
+}
+
+func HandleEditRecommendationModalResponse(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	bookId := strings.TrimPrefix(i.ModalSubmitData().CustomID, bookEditModalPrefix)
+
+	var r bookRecommendation
+	err := editBookRecommendationForm.Decode(i.ModalSubmitData(), &r)
+	if err != nil {
+		return respondFormError(s, i, err)
+	}
+
+	var before, book models.BookEntry
+	refusal := ""
+	err = updateClubTable(func(t *models.ClubTable) error {
+		var err error
+		before, err = t.GetBookById(bookId)
+		if err != nil {
+			refusal = fmt.Sprintf("Unable to edit recommendation: %v", err)
+			return nil
+		}
+		// Permissions are checked again since the modal could outlive a role change.
+		if !canManageBook(i, before) {
+			refusal = "Only the member who recommended this book or an organizer can edit it."
+			return nil
+		}
+		book, err = controllers.UpdateBook(t.BookPool, bookId, r.Title, r.Author, r.GoodreadsLink, r.Description, r.Genre)
+		if err != nil {
+			return fmt.Errorf("Unable to update book: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if refusal != "" {
+		return respondEphemeral(s, i, refusal)
+	}
+
+	err = respondEphemeral(s, i, fmt.Sprintf("%s was updated. ✏️", describeRecommendation(i, book)))
+	if err != nil {
+		return err
+	}
+
+	if book.Name != before.Name || book.Author != before.Author {
+		// The catalog is looked up without the club table, then stored in a second short update.
+		metadata, isbn, enriched := lookupBookMetadata(book)
+		if enriched {
+			err = updateClubTable(func(t *models.ClubTable) error {
+				err := controllers.ApplyBookMetadata(t.BookPool, bookId, metadata, isbn)
+				if err != nil {
+					return fmt.Errorf("Unable to store book metadata: %v", err)
+				}
+				book, err = t.GetBookById(bookId)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if book.MessageId == "" {
+		return nil
+	}
+	msg, err := s.ChannelMessage(book.ChannelId, book.MessageId)
+	if err != nil {
+		return fmt.Errorf("Unable to fetch recommendation embed: %v", err)
+	}
+	description := ""
+	if len(msg.Embeds) > 0 {
+		description = msg.Embeds[0].Description
+	}
+	_, err = s.ChannelMessageEditEmbed(book.ChannelId, book.MessageId, bookRecommendationEmbed(book, description))
+	if err != nil {
+		return fmt.Errorf("Unable to update recommendation embed: %v", err)
+	}
+	return nil
+}
+
+func HandleWithdrawRecommendation(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	var book models.BookEntry
+	refusal := ""
+	err := updateClubTable(func(t *models.ClubTable) error {
+		var err error
+		book, err = t.GetBookByIdOrTitle(i.ApplicationCommandData().GetOption("book").StringValue())
+		if err != nil {
+			refusal = fmt.Sprintf("Unable to withdraw recommendation: %v", err)
+			return nil
+		}
+		if !canManageBook(i, book) {
+			refusal = "Only the member who recommended this book or an organizer can withdraw it."
+			return nil
+		}
+		err = controllers.WithdrawBook(t, book.Id)
+		if err != nil {
+			refusal = fmt.Sprintf("Unable to withdraw recommendation: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if refusal != "" {
+		return respondEphemeral(s, i, refusal)
+	}
+
+	err = respondEphemeral(s, i, fmt.Sprintf("%s was withdrawn.", describeRecommendation(i, book)))
+	if err != nil {
+		return err
+	}
+
+	if book.MessageId == "" {
+		return nil
+	}
+	_, err = s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		Channel: book.ChannelId,
+		ID:      book.MessageId,
+		Embeds: &[]*discordgo.MessageEmbed{{
+			Title:       "Book Recommendation Withdrawn",
+			Description: fmt.Sprintf("~~%s by %s~~ was withdrawn and can no longer be voted for.", book.Name, book.Author),
+			Color:       0x808080, // Gray color
+		}},
+		Components: &[]discordgo.MessageComponent{},
+	})
+	if err != nil {
+		log.Println("Unable to update withdrawn recommendation embed:", err)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package views
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/models"
+)
+
+func TestDescribeRecommendation_WordsByActor(t *testing.T) {
+	i := newCommandInteraction()
+	i.Member = &discordgo.Member{User: &discordgo.User{ID: "alice"}}
+	book := models.BookEntry{Name: "Dune", RecommenderId: "alice"}
+
+	if got := describeRecommendation(i, book); got != "Your recommendation of *Dune*" {
+		t.Errorf("Unexpected wording for the recommender: %s", got)
+	}
+
+	book.RecommenderId = "bob"
+	if got := describeRecommendation(i, book); got != "*Dune* (recommended by <@bob>)" {
+		t.Errorf("Unexpected wording for an organizer: %s", got)
+	}
+}
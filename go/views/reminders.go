@@ -0,0 +1,185 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/controllers"
+	"bookclubbot.com/main/models"
+	"bookclubbot.com/main/scheduler"
+)
+
+// meetupRemindersJob checks every minute for reminders that have come due.
+func meetupRemindersJob(s *discordgo.Session) scheduler.Job {
+	return scheduler.Job{
+		Name: "meetup_reminders",
+		Schedule: clubSchedule(func(settings models.ClubSettings) string {
+			return "* * * * *"
+		}),
+		Run: func(due time.Time) error {
+			var t models.ClubTable
+			var reminders []controllers.Reminder
+			// Reminders are recorded before sending, so the next check can't send them again
+			// while they go out. Ones that fail are forgotten afterwards to be retried.
+			err := updateClubTable(func(table *models.ClubTable) error {
+				var err error
+				reminders, err = controllers.TakeDueReminders(table, due)
+				t = *table
+				return err
+			})
+			if err != nil {
+				return err
+			}
+
+			failed := []controllers.Reminder{}
+			for _, reminder := range reminders {
+				err = sendReminder(s, &t, reminder)
+				if err != nil {
+					log.Println("Unable to send reminder:", err)
+					if !isUndeliverable(err) {
+						failed = append(failed, reminder)
+					}
+				}
+			}
+			if len(failed) == 0 {
+				return nil
+			}
+			return updateClubTable(func(table *models.ClubTable) error {
+				for _, reminder := range failed {
+					controllers.ForgetReminder(table, reminder)
+				}
+				return nil
+			})
+		},
+	}
+}
+
+// isUndeliverable reports whether Discord refused a message for good, as when a member
+// has closed their DMs or the bot can't post in the channel. Those aren't retried.
+func isUndeliverable(err error) bool {
+	var restErr *discordgo.RESTError
+	return errors.As(err, &restErr) && restErr.Response != nil && restErr.Response.StatusCode == http.StatusForbidden
+}
+
+func sendReminder(s *discordgo.Session, t *models.ClubTable, reminder controllers.Reminder) error {
+	content := reminderMessage(t, reminder)
+	if reminder.Recipient != controllers.ReminderChannel {
+		channel, err := s.UserChannelCreate(reminder.Recipient)
+		if err != nil {
+			return fmt.Errorf("Unable to open DM with %s: %w", reminder.Recipient, err)
+		}
+		_, err = s.ChannelMessageSend(channel.ID, content+"\n-# Turn these off with /reminders off.")
+		if err != nil {
+			return fmt.Errorf("Unable to DM %s: %w", reminder.Recipient, err)
+		}
+		return nil
+	}
+
+	mentions := &discordgo.MessageAllowedMentions{}
+	if t.Settings.ReminderRoleId != "" {
+		content = fmt.Sprintf("<@&%s> %s", t.Settings.ReminderRoleId, content)
+		mentions.Roles = []string{t.Settings.ReminderRoleId}
+	}
+	_, err := s.ChannelMessageSendComplex(t.Settings.ReminderChannelId, &discordgo.MessageSend{
+		Content:         content,
+		AllowedMentions: mentions,
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to post reminder: %w", err)
+	}
+	return nil
+}
+
+func reminderMessage(t *models.ClubTable, reminder controllers.Reminder) string {
+	// Discord shows timestamps in each reader's own time zone.
+	lines := []string{fmt.Sprintf("⏰ Book club starts <t:%d:R>: <t:%d:F>", reminder.Start.Unix(), reminder.Start.Unix())}
+	if book, err := t.GetBookById(reminder.Meetup.BookId); err == nil {
+		lines = append(lines, fmt.Sprintf("📖 *%s* by %s", book.Name, book.Author))
+	}
+	if reminder.Meetup.NeedsCafe() {
+		if cafe, err := t.GetCafeById(reminder.Meetup.CafeId); err == nil {
+			lines = append(lines, fmt.Sprintf("📍 %s ([Directions](%s))", cafe.Name, cafe.Link))
+		}
+	}
+	if reminder.Meetup.HasOnlineLocation() {
+		lines = append(lines, fmt.Sprintf("💻 %s", reminder.Meetup.OnlineLocation()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// HandleReminders lets members turn DM reminders on and off and choose when they arrive.
+func HandleReminders(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	userId := interactionUserId(i)
+	subcommand := i.ApplicationCommandData().Options[0]
+	switch subcommand.Name {
+	case "on":
+		err := controllers.SubscribeToReminders(t, userId, true)
+		if err != nil {
+			return respondEphemeral(s, i, err.Error())
+		}
+		return respondEphemeral(s, i, "You'll get meetup reminders by DM. Make sure DMs from server members are allowed.")
+	case "off":
+		err := controllers.SubscribeToReminders(t, userId, false)
+		if err != nil {
+			return respondEphemeral(s, i, err.Error())
+		}
+		return respondEphemeral(s, i, "You won't get meetup reminders by DM anymore.")
+	default:
+		minutes, err := controllers.ParseReminderOffsets(subcommand.GetOption("before").StringValue())
+		if err != nil {
+			return respondEphemeral(s, i, err.Error())
+		}
+		controllers.SetMemberReminderOffsets(t, userId, minutes)
+		return respondEphemeral(s, i, fmt.Sprintf("You'll get a DM %s before each meetup.", controllers.FormatReminderOffsets(minutes)))
+	}
+}
+
+// HandleSetMeetupReminders configures the club's channel reminders. Options left out are unchanged.
+func HandleSetMeetupReminders(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	data := i.ApplicationCommandData()
+	if option := data.GetOption("meetup-time"); option != nil {
+		err := controllers.SetMeetupTime(&t.Settings, option.StringValue())
+		if err != nil {
+			return respondEphemeral(s, i, fmt.Sprintf("Unable to change reminders: %v", err))
+		}
+	}
+	if option := data.GetOption("before"); option != nil {
+		minutes, err := controllers.ParseReminderOffsets(option.StringValue())
+		if err != nil {
+			return respondEphemeral(s, i, fmt.Sprintf("Unable to change reminders: %v", err))
+		}
+		t.Settings.ReminderOffsetMinutes = minutes
+	}
+	if option := data.GetOption("channel"); option != nil {
+		t.Settings.ReminderChannelId = option.ChannelValue(nil).ID
+	}
+	if option := data.GetOption("role"); option != nil {
+		t.Settings.ReminderRoleId = option.RoleValue(nil, "").ID
+	}
+	if option := data.GetOption("off"); option != nil && option.BoolValue() {
+		t.Settings.ReminderChannelId = ""
+	}
+
+	meetup_time := t.Settings.MeetupTime
+	if meetup_time == "" {
+		meetup_time = controllers.DefaultMeetupTime
+	}
+	offsets := t.Settings.ReminderOffsetMinutes
+	if len(offsets) == 0 {
+		offsets = controllers.DefaultReminderOffsetMinutes()
+	}
+	if t.Settings.ReminderChannelId == "" {
+		return respondEphemeral(s, i, fmt.Sprintf("Meetups start at %s. Channel reminders are off, but members can still get DMs %s before.", meetup_time, controllers.FormatReminderOffsets(offsets)))
+	}
+	reply := fmt.Sprintf("Meetups start at %s. Reminders go to <#%s> %s before", meetup_time, t.Settings.ReminderChannelId, controllers.FormatReminderOffsets(offsets))
+	if t.Settings.ReminderRoleId != "" {
+		reply += fmt.Sprintf(" and mention <@&%s>", t.Settings.ReminderRoleId)
+	}
+	return respondEphemeral(s, i, reply+".")
+}
@@ -0,0 +1,60 @@
+package views
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"bookclubbot.com/main/controllers"
+	"bookclubbot.com/main/models"
+)
+
+func TestReminderMessage_DescribesMeetup(t *testing.T) {
+	table := models.ClubTable{
+		BookPool: []models.BookEntry{{Id: "b1", Name: "Piranesi", Author: "Susanna Clarke"}},
+		CafePool: []models.CafeEntry{{Id: "c1", Name: "Bean There", Link: "https://maps.example/bean"}},
+	}
+	start := time.Date(2026, 10, 24, 14, 0, 0, 0, time.UTC)
+	reminder := controllers.Reminder{
+		Meetup:    models.ScheduleEntry{Id: "s1", Date: "2026-10-24", BookId: "b1", CafeId: "c1"},
+		Start:     start,
+		Recipient: controllers.ReminderChannel,
+		Offset:    2 * time.Hour,
+	}
+
+	message := reminderMessage(&table, reminder)
+	for _, expected := range []string{"<t:1792850400:R>", "<t:1792850400:F>", "*Piranesi* by Susanna Clarke", "Bean There"} {
+		if !strings.Contains(message, expected) {
+			t.Errorf("Expected the reminder to contain %q, got %q", expected, message)
+		}
+	}
+}
+
+func TestSendReminder_TellsClosedDMsFromOutages(t *testing.T) {
+	table := models.ClubTable{}
+	reminder := controllers.Reminder{Meetup: models.ScheduleEntry{Id: "s1"}, Recipient: "alice"}
+	s, transport := newRecordingSession()
+
+	transport.respond = func(request recordedRequest) (int, string) {
+		if strings.HasSuffix(request.Path, "/messages") {
+			return http.StatusForbidden, `{"code": 50007, "message": "Cannot send messages to this user"}`
+		}
+		return http.StatusOK, `{"id": "dm"}`
+	}
+	err := sendReminder(s, &table, reminder)
+	if err == nil || !isUndeliverable(err) {
+		t.Errorf("Expected closed DMs not to be retried, got %v", err)
+	}
+
+	transport.respond = func(request recordedRequest) (int, string) {
+		if strings.HasSuffix(request.Path, "/messages") {
+			return http.StatusBadGateway, `{}`
+		}
+		return http.StatusOK, `{"id": "dm"}`
+	}
+	err = sendReminder(s, &table, reminder)
+	if err == nil || isUndeliverable(err) {
+		t.Errorf("Expected an outage to be retried, got %v", err)
+	}
+}
@@ -0,0 +1,279 @@
+package views
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/models"
+)
+
+// Discord drops interactions that aren't acknowledged within three seconds, so
+// handlers that are still running after this long get a deferred response.
+var acknowledgeDeadline = 2 * time.Second
+
+// interactionResponder makes sure every interaction is acknowledged exactly once,
+// whether by its handler, by a deferred response or by an error message.
+type interactionResponder struct {
+	mu           sync.Mutex
+	acknowledged bool
+	// The kind of deferred response that was sent, or 0 if the handler answered in time.
+	deferred discordgo.InteractionResponseType
+	// Whether the deferred response has been replaced by the handler's own.
+	completed bool
+	// Responses kept back while the handler has the club table, see holdResponses.
+	holding bool
+	held    []*discordgo.InteractionResponse
+}
+
+var responders = struct {
+	sync.Mutex
+	byId map[string]*interactionResponder
+}{byId: map[string]*interactionResponder{}}
+
+func getResponder(i *discordgo.InteractionCreate) *interactionResponder {
+	responders.Lock()
+	defer responders.Unlock()
+	return responders.byId[i.ID]
+}
+
+// handleInteraction runs a handler so that the user always gets an answer. Slow handlers
+// are deferred, and errors are logged with a reference the user can quote to organizers.
+func handleInteraction(s *discordgo.Session, i *discordgo.InteractionCreate, name string, handler func(s *discordgo.Session, i *discordgo.InteractionCreate) error) {
+	responder := &interactionResponder{}
+	responders.Lock()
+	responders.byId[i.ID] = responder
+	responders.Unlock()
+	defer func() {
+		responders.Lock()
+		delete(responders.byId, i.ID)
+		responders.Unlock()
+	}()
+
+	timer := time.AfterFunc(acknowledgeDeadline, func() {
+		err := responder.deferResponse(s, i)
+		if err != nil {
+			log.Println("Unable to defer response for", name, ":", err)
+		}
+	})
+	err := handler(s, i)
+	timer.Stop()
+
+	if err != nil {
+		reference := errorReference()
+		log.Printf("Error handling %s [%s]: %v", name, reference, err)
+		err = respondEphemeral(s, i, fmt.Sprintf("Something went wrong. If it keeps happening, let an organizer know (reference `%s`).", reference))
+		if err != nil {
+			log.Printf("Unable to report error [%s] to the user: %v", reference, err)
+		}
+		return
+	}
+
+	if !responder.isAcknowledged() {
+		log.Println("Handler for", name, "returned without responding")
+		err = responder.acknowledge(s, i)
+		if err != nil {
+			log.Println("Unable to acknowledge", name, ":", err)
+		}
+	}
+}
+
+// errorReference is a short ID linking an error message to its log entry.
+func errorReference() string {
+	return models.GenerateId()[:8]
+}
+
+func (r *interactionResponder) isAcknowledged() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.acknowledged
+}
+
+// deferredResponseType is the placeholder Discord shows while a handler is still running.
+// Interactions on a message keep the message as is, everything else gets a private "thinking" reply.
+func deferredResponseType(i *discordgo.InteractionCreate) discordgo.InteractionResponseType {
+	if i.Type == discordgo.InteractionMessageComponent || (i.Type == discordgo.InteractionModalSubmit && i.Message != nil) {
+		return discordgo.InteractionResponseDeferredMessageUpdate
+	}
+	return discordgo.InteractionResponseDeferredChannelMessageWithSource
+}
+
+func (r *interactionResponder) deferResponse(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.acknowledged {
+		return nil
+	}
+	response := &discordgo.InteractionResponse{Type: deferredResponseType(i)}
+	if response.Type == discordgo.InteractionResponseDeferredChannelMessageWithSource {
+		response.Data = &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral}
+	}
+	err := s.InteractionRespond(i.Interaction, response)
+	if err != nil {
+		return err
+	}
+	r.acknowledged = true
+	r.deferred = response.Type
+	return nil
+}
+
+// acknowledge quietly answers an interaction whose handler didn't respond at all.
+func (r *interactionResponder) acknowledge(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	if deferredResponseType(i) == discordgo.InteractionResponseDeferredMessageUpdate {
+		return r.respond(s, i, &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate})
+	}
+	return r.respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: "Done!",
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// respond sends a handler's response, translating it into an edit or a follow-up
+// message if the interaction was already acknowledged.
+func (r *interactionResponder) respond(s *discordgo.Session, i *discordgo.InteractionCreate, response *discordgo.InteractionResponse) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.acknowledged {
+		err := s.InteractionRespond(i.Interaction, response)
+		if err != nil {
+			return err
+		}
+		r.acknowledged = true
+		return nil
+	}
+
+	data := response.Data
+	if data == nil {
+		data = &discordgo.InteractionResponseData{}
+	}
+	placeholder := r.deferred == discordgo.InteractionResponseDeferredChannelMessageWithSource && !r.completed
+	switch {
+	case response.Type == discordgo.InteractionResponseModal:
+		return fmt.Errorf("A form can't be opened after the interaction was acknowledged")
+	case response.Type == discordgo.InteractionResponseDeferredMessageUpdate:
+		return nil
+	case response.Type == discordgo.InteractionResponseUpdateMessage:
+		if r.deferred != discordgo.InteractionResponseDeferredMessageUpdate {
+			return fmt.Errorf("The message can no longer be updated")
+		}
+		_, err := s.InteractionResponseEdit(i.Interaction, webhookEdit(data))
+		if err != nil {
+			return err
+		}
+	case placeholder && data.Flags&discordgo.MessageFlagsEphemeral != 0:
+		_, err := s.InteractionResponseEdit(i.Interaction, webhookEdit(data))
+		if err != nil {
+			return err
+		}
+	default:
+		if placeholder {
+			// The private placeholder can't be made public, so it is replaced by a new message.
+			err := s.InteractionResponseDelete(i.Interaction)
+			if err != nil {
+				return err
+			}
+		}
+		_, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+			Content:         data.Content,
+			Embeds:          data.Embeds,
+			Components:      data.Components,
+			AllowedMentions: data.AllowedMentions,
+			Flags:           data.Flags,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	r.completed = true
+	return nil
+}
+
+// webhookEdit edits only the parts of a message that a response sets, like an update response would.
+func webhookEdit(data *discordgo.InteractionResponseData) *discordgo.WebhookEdit {
+	edit := &discordgo.WebhookEdit{AllowedMentions: data.AllowedMentions}
+	if data.Content != "" {
+		edit.Content = &data.Content
+	}
+	if data.Embeds != nil {
+		edit.Embeds = &data.Embeds
+	}
+	if data.Components != nil {
+		edit.Components = &data.Components
+	}
+	return edit
+}
+
+// hold keeps a response back if responses are being held, reporting whether it did.
+func (r *interactionResponder) hold(response *discordgo.InteractionResponse) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.holding {
+		r.held = append(r.held, response)
+	}
+	return r.holding
+}
+
+// holdResponses keeps an interaction's responses back until releaseResponses. Handlers
+// changing the club table use it so members only hear about changes that were saved,
+// and so the table isn't kept locked while Discord is called.
+func holdResponses(i *discordgo.InteractionCreate) {
+	responder := getResponder(i)
+	if responder == nil {
+		return
+	}
+	responder.mu.Lock()
+	defer responder.mu.Unlock()
+	responder.holding = true
+}
+
+// releaseResponses sends the responses kept back by holdResponses, or drops them.
+func releaseResponses(s *discordgo.Session, i *discordgo.InteractionCreate, send bool) error {
+	responder := getResponder(i)
+	if responder == nil {
+		return nil
+	}
+	responder.mu.Lock()
+	held := responder.held
+	responder.holding = false
+	responder.held = nil
+	responder.mu.Unlock()
+
+	if !send {
+		return nil
+	}
+	for _, response := range held {
+		err := responder.respond(s, i, response)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// respond answers an interaction, coping with it having been deferred or answered already.
+func respond(s *discordgo.Session, i *discordgo.InteractionCreate, response *discordgo.InteractionResponse) error {
+	responder := getResponder(i)
+	if responder == nil {
+		return s.InteractionRespond(i.Interaction, response)
+	}
+	if responder.hold(response) {
+		return nil
+	}
+	return responder.respond(s, i, response)
+}
+
+// deferResponse acknowledges an interaction straight away, for handlers about to do
+// something slow. Their response then replaces the "thinking" placeholder.
+func deferResponse(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	responder := getResponder(i)
+	if responder == nil {
+		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{Type: deferredResponseType(i)})
+	}
+	return responder.deferResponse(s, i)
+}
@@ -0,0 +1,155 @@
+package views
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+type recordedRequest struct {
+	Method string
+	Path   string
+	Body   map[string]any
+}
+
+// recordingTransport answers every Discord API call successfully, unless respond says
+// otherwise, and remembers it.
+type recordingTransport struct {
+	mu       sync.Mutex
+	requests []recordedRequest
+	respond  func(request recordedRequest) (int, string)
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	request := recordedRequest{Method: req.Method, Path: req.URL.Path}
+	if req.Body != nil {
+		body, _ := io.ReadAll(req.Body)
+		json.Unmarshal(body, &request.Body)
+	}
+	rt.mu.Lock()
+	rt.requests = append(rt.requests, request)
+	rt.mu.Unlock()
+	status, body := http.StatusOK, `{"id": "1"}`
+	if rt.respond != nil {
+		status, body = rt.respond(request)
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func (rt *recordingTransport) recorded() []recordedRequest {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return append([]recordedRequest{}, rt.requests...)
+}
+
+func newRecordingSession() (*discordgo.Session, *recordingTransport) {
+	transport := &recordingTransport{}
+	s, _ := discordgo.New("Bot test")
+	s.Client = &http.Client{Transport: transport}
+	return s, transport
+}
+
+func newCommandInteraction() *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:    "interaction-1",
+		AppID: "app-1",
+		Token: "token-1",
+		Type:  discordgo.InteractionApplicationCommand,
+	}}
+}
+
+func TestHandleInteraction_ReportsErrorsWithReference(t *testing.T) {
+	s, transport := newRecordingSession()
+	handleInteraction(s, newCommandInteraction(), "command test", func(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+		return errors.New("the club table is on fire")
+	})
+
+	requests := transport.recorded()
+	if len(requests) != 1 || !strings.HasSuffix(requests[0].Path, "/callback") {
+		t.Fatalf("Expected a single interaction response, got %+v", requests)
+	}
+	data := requests[0].Body["data"].(map[string]any)
+	content := data["content"].(string)
+	if !strings.Contains(content, "reference `") || strings.Contains(content, "fire") {
+		t.Errorf("Expected a reference without the error details, got %q", content)
+	}
+	if int(data["flags"].(float64))&int(discordgo.MessageFlagsEphemeral) == 0 {
+		t.Errorf("Expected the error to be ephemeral")
+	}
+}
+
+func TestHandleInteraction_AcknowledgesSilentHandlers(t *testing.T) {
+	s, transport := newRecordingSession()
+	handleInteraction(s, newCommandInteraction(), "command test", func(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+		return nil
+	})
+
+	requests := transport.recorded()
+	if len(requests) != 1 || !strings.HasSuffix(requests[0].Path, "/callback") {
+		t.Fatalf("Expected a single interaction response, got %+v", requests)
+	}
+}
+
+func TestHandleInteraction_DefersSlowHandlers(t *testing.T) {
+	original := acknowledgeDeadline
+	acknowledgeDeadline = 10 * time.Millisecond
+	defer func() { acknowledgeDeadline = original }()
+
+	s, transport := newRecordingSession()
+	handleInteraction(s, newCommandInteraction(), "command test", func(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+		time.Sleep(50 * time.Millisecond)
+		return respondEphemeral(s, i, "Finally done")
+	})
+
+	requests := transport.recorded()
+	if len(requests) != 2 {
+		t.Fatalf("Expected a deferred response and an edit, got %+v", requests)
+	}
+	if requests[0].Body["type"].(float64) != float64(discordgo.InteractionResponseDeferredChannelMessageWithSource) {
+		t.Errorf("Expected a deferred response first, got %+v", requests[0])
+	}
+	if requests[1].Method != http.MethodPatch || !strings.HasSuffix(requests[1].Path, "/messages/@original") {
+		t.Errorf("Expected the placeholder to be edited, got %+v", requests[1])
+	}
+	if requests[1].Body["content"] != "Finally done" {
+		t.Errorf("Unexpected edit %+v", requests[1].Body)
+	}
+}
+
+func TestHandleInteraction_PublicResponseReplacesPlaceholder(t *testing.T) {
+	original := acknowledgeDeadline
+	acknowledgeDeadline = 10 * time.Millisecond
+	defer func() { acknowledgeDeadline = original }()
+
+	s, transport := newRecordingSession()
+	handleInteraction(s, newCommandInteraction(), "command test", func(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+		time.Sleep(50 * time.Millisecond)
+		return respond(s, i, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{Content: "Everyone can see this"},
+		})
+	})
+
+	requests := transport.recorded()
+	if len(requests) != 3 {
+		t.Fatalf("Expected a deferred response, a delete and a follow-up, got %+v", requests)
+	}
+	if requests[1].Method != http.MethodDelete {
+		t.Errorf("Expected the placeholder to be deleted, got %+v", requests[1])
+	}
+	if requests[2].Method != http.MethodPost || requests[2].Body["content"] != "Everyone can see this" {
+		t.Errorf("Expected a public follow-up, got %+v", requests[2])
+	}
+}
@@ -0,0 +1,116 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/controllers"
+	"bookclubbot.com/main/models"
+)
+
+// HandleRSVPButton records a member's answer from the buttons on an announcement.
+func HandleRSVPButton(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	target := strings.TrimPrefix(i.MessageComponentData().CustomID, rsvpButtonPrefix)
+	answer, scheduleId, found := strings.Cut(target, "_")
+	if !found {
+		return fmt.Errorf("Malformed RSVP button: %s", target)
+	}
+	status, err := controllers.ParseRSVPStatus(answer)
+	if err != nil {
+		return err
+	}
+	err = controllers.SetRSVP(t, scheduleId, interactionUserId(i), status)
+	if err != nil {
+		return respondEphemeral(s, i, err.Error())
+	}
+
+	meetup, _ := t.GetScheduleById(scheduleId)
+	going, maybe := t.Headcount(scheduleId)
+	switch status {
+	case models.RSVPGoing:
+		return respondEphemeral(s, i, fmt.Sprintf("See you on %s! %d going and %d maybe so far.", meetup.Date, going, maybe))
+	case models.RSVPMaybe:
+		return respondEphemeral(s, i, fmt.Sprintf("Got it, maybe on %s. %d going and %d maybe so far.", meetup.Date, going, maybe))
+	default:
+		return respondEphemeral(s, i, fmt.Sprintf("Sorry you can't make it on %s. Thanks for letting us know!", meetup.Date))
+	}
+}
+
+// HandleWhoIsComing lists the RSVPs for a meetup, the next one unless another is picked.
+func HandleWhoIsComing(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	var meetup models.ScheduleEntry
+	if option := i.ApplicationCommandData().GetOption("meetup"); option != nil {
+		var err error
+		meetup, err = t.GetScheduleById(option.StringValue())
+		if err != nil {
+			return respondEphemeral(s, i, "That meetup isn't on the schedule. Pick one from the suggestions.")
+		}
+	} else {
+		index, err := controllers.NextMeetup(t.Schedule, time.Now())
+		if err != nil {
+			return respondEphemeral(s, i, err.Error())
+		}
+		meetup = t.Schedule[index]
+	}
+	return respondEphemeral(s, i, describeRSVPs(t, meetup))
+}
+
+// describeMeetup names a meetup by its date and book, e.g. "October 24, 2026: Piranesi".
+func describeMeetup(t *models.ClubTable, meetup models.ScheduleEntry) string {
+	book, err := t.GetBookById(meetup.BookId)
+	if err != nil {
+		return fmt.Sprintf("%s: TBD", meetup.Date)
+	}
+	return fmt.Sprintf("%s: %s", meetup.Date, book.Name)
+}
+
+func describeRSVPs(t *models.ClubTable, meetup models.ScheduleEntry) string {
+	members := map[models.RSVPStatus][]string{}
+	for _, r := range t.GetRSVPs(meetup.Id) {
+		members[r.Status] = append(members[r.Status], fmt.Sprintf("<@%s>", r.UserId))
+	}
+	list := func(label string, status models.RSVPStatus) string {
+		if len(members[status]) == 0 {
+			return fmt.Sprintf("%s (0)", label)
+		}
+		return fmt.Sprintf("%s (%d): %s", label, len(members[status]), strings.Join(members[status], ", "))
+	}
+
+	lines := []string{fmt.Sprintf("**%s**", describeMeetup(t, meetup))}
+	cafe, err := t.GetCafeById(meetup.CafeId)
+	if meetup.NeedsCafe() && err == nil {
+		if cafe.Capacity > 0 {
+			lines = append(lines, fmt.Sprintf("📍 %s (seats %d)", cafe.Name, cafe.Capacity))
+		} else {
+			lines = append(lines, fmt.Sprintf("📍 %s", cafe.Name))
+		}
+	}
+	lines = append(lines,
+		list("✅ Going", models.RSVPGoing),
+		list("🤔 Maybe", models.RSVPMaybe),
+		list("❌ Can't make it", models.RSVPNo),
+	)
+	expected := len(members[models.RSVPGoing]) + len(members[models.RSVPMaybe])
+	if meetup.NeedsCafe() && err == nil && !controllers.CafeFits(cafe, expected) {
+		lines = append(lines, fmt.Sprintf("⚠️ Up to %d members may come, but %s only seats %d.", expected, cafe.Name, cafe.Capacity))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func HandleSetCafeCapacity(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	data := i.ApplicationCommandData()
+	cafeId := data.GetOption("cafe").StringValue()
+	capacity := int(data.GetOption("capacity").IntValue())
+	err := controllers.SetCafeCapacity(t.CafePool, cafeId, capacity)
+	if err != nil {
+		return respondEphemeral(s, i, "Unable to find that cafe. Pick one from the suggestions.")
+	}
+	cafe, _ := t.GetCafeById(cafeId)
+	if capacity == 0 {
+		return respondEphemeral(s, i, fmt.Sprintf("%s's capacity is now unknown.", cafe.Name))
+	}
+	return respondEphemeral(s, i, fmt.Sprintf("%s seats %d. Meetups expecting more will be planned elsewhere.", cafe.Name, capacity))
+}
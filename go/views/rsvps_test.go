@@ -0,0 +1,35 @@
+package views
+
+import (
+	"strings"
+	"testing"
+
+	"bookclubbot.com/main/models"
+)
+
+func TestDescribeRSVPs_WarnsWhenCafeIsTooSmall(t *testing.T) {
+	meetup := models.ScheduleEntry{Id: "s1", Date: "October 24, 2026", BookId: "b1", CafeId: "c1"}
+	table := models.ClubTable{
+		Schedule: []models.ScheduleEntry{meetup},
+		BookPool: []models.BookEntry{{Id: "b1", Name: "Piranesi"}},
+		CafePool: []models.CafeEntry{{Id: "c1", Name: "Bean There", Capacity: 2}},
+		RSVPs: []models.RSVPEntry{
+			{ScheduleId: "s1", UserId: "alice", Status: models.RSVPGoing},
+			{ScheduleId: "s1", UserId: "bob", Status: models.RSVPGoing},
+			{ScheduleId: "s1", UserId: "carol", Status: models.RSVPMaybe},
+		},
+	}
+
+	description := describeRSVPs(&table, meetup)
+	for _, expected := range []string{
+		"October 24, 2026: Piranesi",
+		"Going (2): <@alice>, <@bob>",
+		"Maybe (1): <@carol>",
+		"Can't make it (0)",
+		"only seats 2",
+	} {
+		if !strings.Contains(description, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, description)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/controllers"
+	"bookclubbot.com/main/models"
+)
+
+func HandleContentWarning(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	data := i.ApplicationCommandData()
+	warning := data.GetOption("warning").StringValue()
+
+	book, err := t.GetBookByIdOrTitle(data.GetOption("book").StringValue())
+	if err != nil {
+		return respondEphemeral(s, i, fmt.Sprintf("Unable to add content warning: %v", err))
+	}
+	err = controllers.AddContentWarning(t.BookPool, book.Id, warning)
+	if err != nil {
+		return respondEphemeral(s, i, fmt.Sprintf("Unable to add content warning: %v", err))
+	}
+
+	return respondEphemeral(s, i, fmt.Sprintf("Added a content warning to *%s*. Thank you for looking out for the club!", book.Name))
+}
+
+func HandleVeto(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	userId := interactionUserId(i)
+	now := time.Now()
+
+	book, err := t.GetBookByIdOrTitle(i.ApplicationCommandData().GetOption("book").StringValue())
+	if err != nil {
+		return respondEphemeral(s, i, fmt.Sprintf("Unable to veto: %v", err))
+	}
+	err = controllers.CastVeto(t, userId, book.Id, now)
+	if errors.Is(err, controllers.ErrNoVetoesLeft) {
+		return respondEphemeral(s, i, fmt.Sprintf("%v. Your vetoes reset next season.", err))
+	}
+	if err != nil {
+		return respondEphemeral(s, i, fmt.Sprintf("Unable to veto: %v", err))
+	}
+
+	return respondEphemeral(s, i, fmt.Sprintf(
+		"Your veto of *%s* was recorded anonymously. You have %d left this season.",
+		book.Name, controllers.VetoesLeft(t, userId, now),
+	))
+}
+
+func HandleVetoSummary(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	now := time.Now()
+	counts := controllers.VetoCounts(t, now)
+
+	lines := []string{
+		"**🚫 Vetoes and content warnings**",
+		fmt.Sprintf("Members get %d vetoes per season. Books with %d or more this season (%s) are excluded.", t.Settings.VetoesPerSeason, t.Settings.VetoThreshold, controllers.SeasonOf(now)),
+		"",
+	}
+	books := append([]models.BookEntry{}, t.BookPool...)
+	sort.SliceStable(books, func(a, b int) bool {
+		return counts[books[a].Id] > counts[books[b].Id]
+	})
+	listed := 0
+	for _, book := range books {
+		if book.Read || (counts[book.Id] == 0 && len(book.ContentWarnings) == 0) {
+			continue
+		}
+		line := fmt.Sprintf("- *%s*: %d vetoes this season", book.Name, counts[book.Id])
+		if book.Vetoed {
+			line += " (excluded)"
+		}
+		if len(book.ContentWarnings) > 0 {
+			line += fmt.Sprintf(" · CW: %s", strings.Join(book.ContentWarnings, ", "))
+		}
+		lines = append(lines, line)
+		listed++
+	}
+	if listed == 0 {
+		lines = append(lines, "No unread books have vetoes or content warnings.")
+	}
+	return respondEphemeral(s, i, strings.Join(lines, "\n"))
+}
+
+func HandleSetVetoRules(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	data := i.ApplicationCommandData()
+
+	vetoes_per_season := t.Settings.VetoesPerSeason
+	if option := data.GetOption("vetoes-per-season"); option != nil {
+		vetoes_per_season = int(option.IntValue())
+	}
+	threshold := t.Settings.VetoThreshold
+	if option := data.GetOption("threshold"); option != nil {
+		threshold = int(option.IntValue())
+	}
+
+	err := controllers.SetVetoRules(t, vetoes_per_season, threshold, time.Now())
+	if err != nil {
+		return respondEphemeral(s, i, fmt.Sprintf("Unable to change veto rules: %v", err))
+	}
+
+	return respondEphemeral(s, i, fmt.Sprintf(
+		"Members now get %s per season. Books are excluded at %s.",
+		describeLimit(vetoes_per_season, "vetoes"),
+		describeLimit(threshold, "vetoes"),
+	))
+}
@@ -0,0 +1,367 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/controllers"
+	"bookclubbot.com/main/models"
+)
+
+const voteEmoji = "❤️"
+
+const voteTallyFieldName = "Votes"
+
+const (
+	voteButtonPrefix        = "book_vote_"
+	unvoteButtonPrefix      = "book_unvote_"
+	bookDetailsButtonPrefix = "book_details_"
+)
+
+// Recommendation embeds carry the book ID in their footer so votes survive title edits.
+const bookIdFooterPrefix = "Book ID: "
+
+// Discord clients send the heart both with and without the emoji variation selector.
+func isVoteEmoji(name string) bool {
+	return strings.TrimSuffix(name, "\ufe0f") == strings.TrimSuffix(voteEmoji, "\ufe0f")
+}
+
+func HandleVotingReactions(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+	log.Println("HandleVotingReactions")
+	// Ignore the bot's own reactions
+	if r.UserID == s.State.User.ID {
+		return
+	}
+	if !isVoteEmoji(r.Emoji.Name) {
+		return
+	}
+	recountVotes(s, r.ChannelID, r.MessageID)
+}
+
+func HandleVotingReactionRemove(s *discordgo.Session, r *discordgo.MessageReactionRemove) {
+	log.Println("HandleVotingReactionRemove")
+	if r.UserID == s.State.User.ID {
+		return
+	}
+	if !isVoteEmoji(r.Emoji.Name) {
+		return
+	}
+	recountVotes(s, r.ChannelID, r.MessageID)
+}
+
+func HandleVotingReactionRemoveAll(s *discordgo.Session, r *discordgo.MessageReactionRemoveAll) {
+	log.Println("HandleVotingReactionRemoveAll")
+	recountVotes(s, r.ChannelID, r.MessageID)
+}
+
+// recountVotes recomputes the vote count for a recommendation message from the API
+// and stores it on the matching book. The cache is skipped since it can be stale.
+func recountVotes(s *discordgo.Session, channelID string, messageID string) {
+	msg, err := s.ChannelMessage(channelID, messageID)
+	if err != nil {
+		log.Println("Could not fetch message:", err)
+		return
+	}
+
+	// Skip reaction on messages that aren't from the bot
+	if msg.Author.ID != s.State.User.ID {
+		return
+	}
+
+	// Recommendations with vote buttons keep their tally in the ledger, not in reactions.
+	if len(msg.Components) > 0 {
+		return
+	}
+
+	// Get the book title from the embed
+	if len(msg.Embeds) == 0 {
+		log.Println("No embeds found in the message")
+		return
+	}
+
+	voters, err := listVoters(s, msg)
+	if err != nil {
+		log.Println("Could not count votes:", err)
+		return
+	}
+
+	var rejected []string
+	err = updateClubTable(func(t *models.ClubTable) error {
+		book, err := resolveVotedBook(t, msg)
+		if err != nil {
+			return fmt.Errorf("Could not find the book for this message: %v", err)
+		}
+
+		log.Println("Updating votes for book:", book.Name, "to", len(voters))
+		rejected, err = controllers.SyncBookVotes(t, book.Id, voters, time.Now())
+		if err != nil {
+			return fmt.Errorf("Error updating book vote count: %v", err)
+		}
+		err = controllers.SetBookMessage(t.BookPool, book.Id, msg.ChannelID, msg.ID)
+		if err != nil {
+			return fmt.Errorf("Error linking book to its message: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	// Members over the vote cap get their reaction taken back so the message matches the ledger.
+	for _, userId := range rejected {
+		for _, react := range msg.Reactions {
+			if !isVoteEmoji(react.Emoji.Name) {
+				continue
+			}
+			err := s.MessageReactionRemove(msg.ChannelID, msg.ID, react.Emoji.APIName(), userId)
+			if err != nil {
+				log.Println("Could not remove capped vote:", err)
+			}
+		}
+	}
+}
+
+// resolveVotedBook finds the book a recommendation message is for. Books are
+// matched by the stored message ID first, then by the book ID in the embed
+// footer. Recommendations posted before IDs were recorded fall back to the title.
+func resolveVotedBook(t *models.ClubTable, msg *discordgo.Message) (models.BookEntry, error) {
+	if book, err := t.GetBookByMessageId(msg.ID); err == nil {
+		return book, nil
+	}
+	for _, embed := range msg.Embeds {
+		if embed.Footer != nil && strings.HasPrefix(embed.Footer.Text, bookIdFooterPrefix) {
+			return t.GetBookById(strings.TrimPrefix(embed.Footer.Text, bookIdFooterPrefix))
+		}
+	}
+	for _, embed := range msg.Embeds {
+		for _, field := range embed.Fields {
+			if field.Name != "Title" {
+				continue
+			}
+			for _, book := range t.BookPool {
+				if book.Name == field.Value && book.MessageId == "" {
+					return book, nil
+				}
+			}
+		}
+	}
+	return models.BookEntry{}, fmt.Errorf("No book found for message %s", msg.ID)
+}
+
+// listVoters returns the distinct members, excluding the bot's own seed
+// reaction, who reacted to the message with a heart.
+func listVoters(s *discordgo.Session, msg *discordgo.Message) ([]string, error) {
+	const page_size = 100
+	voters := []string{}
+	seen := map[string]bool{}
+	for _, react := range msg.Reactions {
+		if !isVoteEmoji(react.Emoji.Name) {
+			continue
+		}
+		after := ""
+		for {
+			users, err := s.MessageReactions(msg.ChannelID, msg.ID, react.Emoji.APIName(), page_size, "", after)
+			if err != nil {
+				return nil, fmt.Errorf("Unable to list reactions for message %s: %v", msg.ID, err)
+			}
+			for _, user := range users {
+				if user.ID == s.State.User.ID || seen[user.ID] {
+					continue
+				}
+				seen[user.ID] = true
+				voters = append(voters, user.ID)
+			}
+			if len(users) < page_size {
+				break
+			}
+			after = users[len(users)-1].ID
+		}
+	}
+	return voters, nil
+}
+
+// HandleMemberLeave drops the votes of members who leave the server.
+func HandleMemberLeave(s *discordgo.Session, m *discordgo.GuildMemberRemove) {
+	log.Println("HandleMemberLeave")
+	err := updateClubTable(func(t *models.ClubTable) error {
+		return controllers.RemoveMemberVotes(t, m.User.ID)
+	})
+	if err != nil {
+		log.Println("Error removing votes for departed member:", err)
+	}
+}
+
+func HandleMyVotes(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	votes := t.GetVotesByUser(interactionUserId(i))
+	response := "You haven't voted for any books yet. Leave a ❤️ on a recommendation to vote!"
+	if len(votes) > 0 {
+		lines := []string{"**Your votes**", ""}
+		for _, vote := range votes {
+			book, err := t.GetBookById(vote.BookId)
+			if err != nil {
+				return fmt.Errorf("Unable to find voted book: %v", err)
+			}
+			lines = append(lines, fmt.Sprintf("- *%s* by %s (voted %s)", book.Name, book.Author, vote.Timestamp.Format(controllers.TIME_FORMAT)))
+		}
+		response = strings.Join(lines, "\n")
+	}
+
+	return respondEphemeral(s, i, response)
+}
+
+func HandleSetVoteLimit(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	max_votes := int(i.ApplicationCommandData().GetOption("max-votes").IntValue())
+	err := controllers.SetVoteLimit(&t.Settings, max_votes)
+	if err != nil {
+		return respondEphemeral(s, i, fmt.Sprintf("Unable to change the vote limit: %v", err))
+	}
+	if max_votes == 0 {
+		return respondEphemeral(s, i, "Members can vote for as many books as they like.")
+	}
+	return respondEphemeral(s, i, fmt.Sprintf("Members can now vote for up to %d books at a time. Votes for books that were read, archived or vetoed don't count.", max_votes))
+}
+
+func bookVoteButtons(bookId string) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Vote",
+					Style:    discordgo.PrimaryButton,
+					Emoji:    &discordgo.ComponentEmoji{Name: voteEmoji},
+					CustomID: voteButtonPrefix + bookId,
+				},
+				discordgo.Button{
+					Label:    "Unvote",
+					Style:    discordgo.SecondaryButton,
+					CustomID: unvoteButtonPrefix + bookId,
+				},
+				discordgo.Button{
+					Label:    "Details",
+					Style:    discordgo.SecondaryButton,
+					CustomID: bookDetailsButtonPrefix + bookId,
+				},
+			},
+		},
+	}
+}
+
+// withVoteTally returns a copy of a recommendation embed showing the given vote count.
+func withVoteTally(embed *discordgo.MessageEmbed, votes int) *discordgo.MessageEmbed {
+	updated := *embed
+	updated.Fields = []*discordgo.MessageEmbedField{}
+	for _, field := range embed.Fields {
+		if field.Name == voteTallyFieldName {
+			continue
+		}
+		updated.Fields = append(updated.Fields, field)
+	}
+	updated.Fields = append(updated.Fields, &discordgo.MessageEmbedField{
+		Name:  voteTallyFieldName,
+		Value: fmt.Sprintf("%d", votes),
+	})
+	return &updated
+}
+
+func HandleVoteButton(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	bookId := strings.TrimPrefix(i.MessageComponentData().CustomID, voteButtonPrefix)
+
+	err := controllers.AddVote(t, interactionUserId(i), bookId, time.Now())
+	if errors.Is(err, controllers.ErrVoteCapReached) {
+		return respondEphemeral(s, i, fmt.Sprintf("%v. Unvote another book first, then try again.", err))
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to record vote: %v", err)
+	}
+
+	return updateVoteTally(s, i, t, bookId)
+}
+
+func HandleUnvoteButton(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	bookId := strings.TrimPrefix(i.MessageComponentData().CustomID, unvoteButtonPrefix)
+
+	err := controllers.RemoveVote(t, interactionUserId(i), bookId)
+	if err != nil {
+		return fmt.Errorf("Unable to remove vote: %v", err)
+	}
+
+	return updateVoteTally(s, i, t, bookId)
+}
+
+func HandleBookDetailsButton(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable) error {
+	bookId := strings.TrimPrefix(i.MessageComponentData().CustomID, bookDetailsButtonPrefix)
+
+	book, err := t.GetBookById(bookId)
+	if err != nil {
+		return fmt.Errorf("Unable to find book for details: %v", err)
+	}
+
+	voted := "You haven't voted for this book."
+	for _, vote := range t.GetVotesByUser(interactionUserId(i)) {
+		if vote.BookId == bookId {
+			voted = "You voted for this book."
+			break
+		}
+	}
+	lines := []string{
+		fmt.Sprintf("**%s** by %s", book.Name, book.Author),
+		fmt.Sprintf("Votes: %d", book.Votes),
+		voted,
+	}
+	if book.RecommenderId != "" {
+		lines = append(lines, fmt.Sprintf("Recommended by <@%s>", book.RecommenderId))
+	}
+	if len(book.ContentWarnings) > 0 {
+		lines = append(lines, fmt.Sprintf("Content warnings: %s", strings.Join(book.ContentWarnings, ", ")))
+	}
+	if book.Link != "" {
+		lines = append(lines, book.Link)
+	}
+	if book.Description != "" {
+		lines = append(lines, "", book.Description)
+	}
+	return respondEphemeral(s, i, strings.Join(lines, "\n"))
+}
+
+// updateVoteTally edits the recommendation message in place to show the book's current votes.
+func updateVoteTally(s *discordgo.Session, i *discordgo.InteractionCreate, t *models.ClubTable, bookId string) error {
+	book, err := t.GetBookById(bookId)
+	if err != nil {
+		return fmt.Errorf("Unable to find voted book: %v", err)
+	}
+	embeds := []*discordgo.MessageEmbed{}
+	for _, embed := range i.Message.Embeds {
+		embeds = append(embeds, withVoteTally(embed, book.Votes))
+	}
+	err = respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     embeds,
+			Components: bookVoteButtons(bookId),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to update vote tally: %v", err)
+	}
+	return nil
+}
+
+func respondEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, content string) error {
+	err := respond(s, i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to send response: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package views
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"bookclubbot.com/main/models"
+)
+
+func TestIsVoteEmoji(t *testing.T) {
+	if !isVoteEmoji("❤️") {
+		t.Errorf("Heart with variation selector should count as a vote")
+	}
+	if !isVoteEmoji("❤") {
+		t.Errorf("Heart without variation selector should count as a vote")
+	}
+	if isVoteEmoji("👍") {
+		t.Errorf("Other emoji should not count as a vote")
+	}
+}
+
+func TestResolveVotedBook(t *testing.T) {
+	table := models.ClubTable{
+		BookPool: []models.BookEntry{
+			{Id: "book-1", Name: "Duplicate Title", MessageId: "msg-1"},
+			{Id: "book-2", Name: "Duplicate Title"},
+			{Id: "book-3", Name: "Legacy Book"},
+		},
+	}
+	tests := []struct {
+		name   string
+		msg    discordgo.Message
+		wantId string
+	}{
+		{
+			name:   "Matches stored message ID",
+			msg:    discordgo.Message{ID: "msg-1"},
+			wantId: "book-1",
+		},
+		{
+			name: "Matches footer book ID over a colliding title",
+			msg: discordgo.Message{ID: "msg-2", Embeds: []*discordgo.MessageEmbed{{
+				Fields: []*discordgo.MessageEmbedField{{Name: "Title", Value: "Duplicate Title"}},
+				Footer: &discordgo.MessageEmbedFooter{Text: bookIdFooterPrefix + "book-2"},
+			}}},
+			wantId: "book-2",
+		},
+		{
+			name: "Falls back to title for older recommendations",
+			msg: discordgo.Message{ID: "msg-3", Embeds: []*discordgo.MessageEmbed{{
+				Fields: []*discordgo.MessageEmbedField{{Name: "Title", Value: "Legacy Book"}},
+			}}},
+			wantId: "book-3",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := resolveVotedBook(&table, &tc.msg)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got.Id != tc.wantId {
+				t.Errorf("Resolved book %s, want %s", got.Id, tc.wantId)
+			}
+		})
+	}
+
+	_, err := resolveVotedBook(&table, &discordgo.Message{ID: "unknown"})
+	if err == nil {
+		t.Errorf("Expected an error for a message with no book")
+	}
+}
+
+func TestWithVoteTally(t *testing.T) {
+	embed := &discordgo.MessageEmbed{
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Title", Value: "Example Book"},
+			{Name: voteTallyFieldName, Value: "1"},
+		},
+	}
+	updated := withVoteTally(embed, 3)
+	if len(updated.Fields) != 2 {
+		t.Fatalf("Expected the tally to be replaced, got %d fields", len(updated.Fields))
+	}
+	if updated.Fields[1].Value != "3" {
+		t.Errorf("Expected a tally of 3, got %s", updated.Fields[1].Value)
+	}
+	if embed.Fields[1].Value != "1" {
+		t.Errorf("The original embed was modified")
+	}
+}